@@ -10,6 +10,11 @@ type Node interface {
 	// TokenLiteral はデバッグとテストのために使用する
 	TokenLiteral() string
 	String() string
+	// Pos はノードの先頭の位置を返す
+	Pos() token.Pos
+	// End はノードの直後の位置を返す
+	// ノードの範囲は子ノードの範囲をすべて含む
+	End() token.Pos
 }
 
 // この言語の文は 'let 文' と 'return 文' のみ
@@ -40,6 +45,20 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) Pos() token.Pos {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.NoPos
+}
+
+func (p *Program) End() token.Pos {
+	if n := len(p.Statements); n > 0 {
+		return p.Statements[n-1].End()
+	}
+	return token.NoPos
+}
+
 func (p *Program) String() string {
 	var b bytes.Buffer
 	for _, s := range p.Statements {
@@ -56,6 +75,9 @@ type LetStatement struct {
 	Token token.Token
 	Name  *Identifier
 	Value Expression
+	// ';' の位置
+	// 省略されたときは token.NoPos
+	Semicolon token.Pos
 }
 
 // Statement インタフェースを満たす
@@ -65,11 +87,30 @@ func (l *LetStatement) TokenLiteral() string {
 	return l.Token.Literal
 }
 
+func (l *LetStatement) Pos() token.Pos {
+	return l.Token.Pos
+}
+
+func (l *LetStatement) End() token.Pos {
+	if l.Semicolon.IsValid() {
+		return l.Semicolon + 1
+	}
+	if l.Value != nil {
+		return l.Value.End()
+	}
+	if l.Name != nil {
+		return l.Name.End()
+	}
+	return l.Token.End()
+}
+
 func (l *LetStatement) String() string {
 	var b bytes.Buffer
 
 	b.WriteString(l.TokenLiteral() + " ")
-	b.WriteString(l.Name.String())
+	if l.Name != nil {
+		b.WriteString(l.Name.String())
+	}
 	b.WriteString(" = ")
 	if l.Value != nil {
 		b.WriteString(l.Value.String())
@@ -94,6 +135,14 @@ func (i *Identifier) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *Identifier) Pos() token.Pos {
+	return i.Token.Pos
+}
+
+func (i *Identifier) End() token.Pos {
+	return i.Token.End()
+}
+
 func (i *Identifier) String() string {
 	return i.Value
 }
@@ -102,6 +151,9 @@ type ReturnStatement struct {
 	// Token = token.RETURN
 	Token       token.Token
 	ReturnValue Expression
+	// ';' の位置
+	// 省略されたときは token.NoPos
+	Semicolon token.Pos
 }
 
 func (r *ReturnStatement) statementNode() {}
@@ -110,6 +162,20 @@ func (r *ReturnStatement) TokenLiteral() string {
 	return r.Token.Literal
 }
 
+func (r *ReturnStatement) Pos() token.Pos {
+	return r.Token.Pos
+}
+
+func (r *ReturnStatement) End() token.Pos {
+	if r.Semicolon.IsValid() {
+		return r.Semicolon + 1
+	}
+	if r.ReturnValue != nil {
+		return r.ReturnValue.End()
+	}
+	return r.Token.End()
+}
+
 func (r *ReturnStatement) String() string {
 	var b bytes.Buffer
 
@@ -126,6 +192,9 @@ type ExpressionStatement struct {
 	// 式の最初の Token
 	Token      token.Token
 	Expression Expression
+	// 式の後の ';' の位置
+	// 省略されたときは token.NoPos
+	Semicolon token.Pos
 }
 
 func (e *ExpressionStatement) statementNode() {}
@@ -134,6 +203,20 @@ func (e *ExpressionStatement) TokenLiteral() string {
 	return e.Token.Literal
 }
 
+func (e *ExpressionStatement) Pos() token.Pos {
+	return e.Token.Pos
+}
+
+func (e *ExpressionStatement) End() token.Pos {
+	if e.Semicolon.IsValid() {
+		return e.Semicolon + 1
+	}
+	if e.Expression != nil {
+		return e.Expression.End()
+	}
+	return e.Token.End()
+}
+
 func (e *ExpressionStatement) String() string {
 	if e.Expression != nil {
 		return e.Expression.String()
@@ -152,6 +235,14 @@ func (i *IntegerLiteral) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *IntegerLiteral) Pos() token.Pos {
+	return i.Token.Pos
+}
+
+func (i *IntegerLiteral) End() token.Pos {
+	return i.Token.End()
+}
+
 func (i *IntegerLiteral) String() string {
 	return i.Token.Literal
 }
@@ -173,11 +264,25 @@ func (p *PrefixExpression) TokenLiteral() string {
 	return p.Token.Literal
 }
 
+func (p *PrefixExpression) Pos() token.Pos {
+	return p.Token.Pos
+}
+
+func (p *PrefixExpression) End() token.Pos {
+	if p.Right != nil {
+		return p.Right.End()
+	}
+	return p.Token.End()
+}
+
 func (p *PrefixExpression) String() string {
 	var b bytes.Buffer
 	b.WriteString("(")
 	b.WriteString(p.Operator)
-	b.WriteString(p.Right.String())
+	// 構文エラーがあると子ノードが nil になることがある
+	if p.Right != nil {
+		b.WriteString(p.Right.String())
+	}
 	b.WriteString(")")
 	return b.String()
 }
@@ -196,12 +301,31 @@ func (i *InfixExpression) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *InfixExpression) Pos() token.Pos {
+	if i.Left != nil {
+		return i.Left.Pos()
+	}
+	return i.Token.Pos
+}
+
+func (i *InfixExpression) End() token.Pos {
+	if i.Right != nil {
+		return i.Right.End()
+	}
+	return i.Token.End()
+}
+
 func (i *InfixExpression) String() string {
 	var b bytes.Buffer
 	b.WriteString("(")
-	b.WriteString(i.Left.String())
+	// 構文エラーがあると子ノードが nil になることがある
+	if i.Left != nil {
+		b.WriteString(i.Left.String())
+	}
 	b.WriteString(" " + i.Operator + " ")
-	b.WriteString(i.Right.String())
+	if i.Right != nil {
+		b.WriteString(i.Right.String())
+	}
 	b.WriteString(")")
 	return b.String()
 }
@@ -217,6 +341,52 @@ func (b *Boolean) TokenLiteral() string {
 	return b.Token.Literal
 }
 
+func (b *Boolean) Pos() token.Pos {
+	return b.Token.Pos
+}
+
+func (b *Boolean) End() token.Pos {
+	return b.Token.End()
+}
+
 func (b *Boolean) String() string {
 	return b.Token.Literal
 }
+
+// '(' <expression> ')'
+// 括弧の位置を保持するためのノードで、評価の結果は Expression と同じになる
+type GroupedExpression struct {
+	// Token = token.LPAREN
+	Token      token.Token
+	Expression Expression
+	// ')' の位置
+	Rparen token.Pos
+}
+
+func (g *GroupedExpression) expressionNode() {}
+
+func (g *GroupedExpression) TokenLiteral() string {
+	return g.Token.Literal
+}
+
+func (g *GroupedExpression) Pos() token.Pos {
+	return g.Token.Pos
+}
+
+func (g *GroupedExpression) End() token.Pos {
+	if g.Rparen.IsValid() {
+		return g.Rparen + 1
+	}
+	if g.Expression != nil {
+		return g.Expression.End()
+	}
+	return g.Token.End()
+}
+
+// 中置式と前置式は自身で括弧を出力するので、ここでは括弧を追加しない
+func (g *GroupedExpression) String() string {
+	if g.Expression != nil {
+		return g.Expression.String()
+	}
+	return ""
+}
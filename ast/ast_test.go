@@ -27,3 +27,79 @@ func TestString(t *testing.T) {
 		t.Errorf("Program.String() got=%q", program.String())
 	}
 }
+
+func TestNodeAt(t *testing.T) {
+	// 'x + 10;'
+	x := &Identifier{
+		Token: token.Token{Type: token.IDENT, Literal: "x", Pos: token.PosFromOffset(0)},
+		Value: "x",
+	}
+	ten := &IntegerLiteral{
+		Token: token.Token{Type: token.INT, Literal: "10", Pos: token.PosFromOffset(4)},
+		Value: 10,
+	}
+	infix := &InfixExpression{
+		Token:    token.Token{Type: token.PLUS, Literal: "+", Pos: token.PosFromOffset(2)},
+		Left:     x,
+		Operator: "+",
+		Right:    ten,
+	}
+	stmt := &ExpressionStatement{
+		Token:      x.Token,
+		Expression: infix,
+		Semicolon:  token.PosFromOffset(6),
+	}
+	program := &Program{Statements: []Statement{stmt}}
+
+	cases := []struct {
+		offset   int
+		expected Node
+	}{
+		{0, x},
+		{1, infix},
+		{2, infix},
+		{4, ten},
+		{5, ten},
+		{6, stmt},
+		{7, nil},
+	}
+	for _, c := range cases {
+		actual := NodeAt(program, token.PosFromOffset(c.offset))
+		if actual != c.expected {
+			t.Errorf("want NodeAt(%d) = %v, got %v", c.offset, c.expected, actual)
+		}
+	}
+
+	path := PathEnclosing(program, token.PosFromOffset(4))
+	if len(path) != 4 || path[0] != program || path[3] != ten {
+		t.Errorf("want PathEnclosing(4) = [Program ExpressionStatement InfixExpression IntegerLiteral], got %v", path)
+	}
+}
+
+func TestShift(t *testing.T) {
+	// 'let x = (1);'
+	x := &Identifier{Token: token.Token{Type: token.IDENT, Literal: "x", Pos: token.PosFromOffset(4)}, Value: "x"}
+	one := &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1", Pos: token.PosFromOffset(9)}, Value: 1}
+	group := &GroupedExpression{
+		Token:      token.Token{Type: token.LPAREN, Literal: "(", Pos: token.PosFromOffset(8)},
+		Expression: one,
+		Rparen:     token.PosFromOffset(10),
+	}
+	let := &LetStatement{
+		Token: token.Token{Type: token.LET, Literal: "let", Pos: token.PosFromOffset(0)},
+		Name:  x,
+		Value: group,
+	}
+
+	// 前に 'y; ' を挿入する
+	Shift(let, 3)
+	if let.Pos() != token.PosFromOffset(3) || x.Pos() != token.PosFromOffset(7) || one.Pos() != token.PosFromOffset(12) {
+		t.Errorf("want the positions shifted by 3, got let=%d x=%d 1=%d", let.Pos(), x.Pos(), one.Pos())
+	}
+	if group.End() != token.PosFromOffset(14) {
+		t.Errorf("want the ')' shifted by 3, got %d", group.End())
+	}
+	if let.Semicolon != token.NoPos {
+		t.Errorf("want the omitted ';' to stay NoPos, got %d", let.Semicolon)
+	}
+}
@@ -0,0 +1,135 @@
+package ast
+
+import "github.com/hiroygo/go-interpreter/token"
+
+// Visitor は Walk でノードを訪問するたびに呼ばれる
+// Visit が返した Visitor w が nil でなければ、子ノードを w で訪問した後に w.Visit(nil) が呼ばれる
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk は深さ優先で AST をたどる
+// 使い方は go/ast の Walk と同じ
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, s := range n.Statements {
+			walkIfNotNil(v, s)
+		}
+	case *LetStatement:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		walkIfNotNil(v, n.Value)
+	case *ReturnStatement:
+		walkIfNotNil(v, n.ReturnValue)
+	case *ExpressionStatement:
+		walkIfNotNil(v, n.Expression)
+	case *PrefixExpression:
+		walkIfNotNil(v, n.Right)
+	case *InfixExpression:
+		walkIfNotNil(v, n.Left)
+		walkIfNotNil(v, n.Right)
+	case *GroupedExpression:
+		walkIfNotNil(v, n.Expression)
+	case *Identifier, *IntegerLiteral, *Boolean:
+		// 子ノードは無い
+	}
+
+	v.Visit(nil)
+}
+
+// 構文エラーがあると子ノードが nil になることがある
+func walkIfNotNil(v Visitor, n Node) {
+	if n == nil {
+		return
+	}
+	Walk(v, n)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect は深さ優先で AST をたどり、各ノードで f(node) を呼び出す
+// f が true を返したときだけ子ノードを訪問し、その後に f(nil) を呼び出す
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// NodeAt は pos を範囲に含むノードのうち、最も内側のノードを返す
+// 該当するノードが無いときは nil を返す
+// エディタのホバーや選択範囲の拡張に使う
+func NodeAt(root Node, pos token.Pos) Node {
+	path := PathEnclosing(root, pos)
+	if len(path) == 0 {
+		return nil
+	}
+	return path[len(path)-1]
+}
+
+// PathEnclosing は pos を範囲に含むノードを root から内側に向かって順番に返す
+func PathEnclosing(root Node, pos token.Pos) []Node {
+	var path []Node
+	Inspect(root, func(n Node) bool {
+		if n == nil {
+			return false
+		}
+		if pos < n.Pos() || n.End() <= pos {
+			return false
+		}
+		path = append(path, n)
+		return true
+	})
+	return path
+}
+
+// Shift は node とその子ノードの位置を delta だけずらす
+// ソースの前の部分を書き換えたときに、後ろの部分の AST を作り直さずに使う
+func Shift(node Node, delta int) {
+	d := token.Pos(delta)
+	Inspect(node, func(n Node) bool {
+		switch n := n.(type) {
+		case *LetStatement:
+			n.Token.Pos += d
+			n.Semicolon = shiftPos(n.Semicolon, d)
+		case *ReturnStatement:
+			n.Token.Pos += d
+			n.Semicolon = shiftPos(n.Semicolon, d)
+		case *ExpressionStatement:
+			n.Token.Pos += d
+			n.Semicolon = shiftPos(n.Semicolon, d)
+		case *Identifier:
+			n.Token.Pos += d
+		case *IntegerLiteral:
+			n.Token.Pos += d
+		case *Boolean:
+			n.Token.Pos += d
+		case *PrefixExpression:
+			n.Token.Pos += d
+		case *InfixExpression:
+			n.Token.Pos += d
+		case *GroupedExpression:
+			n.Token.Pos += d
+			n.Rparen = shiftPos(n.Rparen, d)
+		}
+		return true
+	})
+}
+
+// 省略された位置はずらさない
+func shiftPos(p, d token.Pos) token.Pos {
+	if !p.IsValid() {
+		return p
+	}
+	return p + d
+}
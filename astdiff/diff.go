@@ -0,0 +1,184 @@
+// astdiff は 2 つの AST の構造的な差分を計算する
+// 空白や位置の違いは無視して、挿入、削除、移動、更新されたノードを報告する
+package astdiff
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+type Kind int
+
+const (
+	Insert Kind = iota
+	Delete
+	Update
+	Move
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	case Update:
+		return "update"
+	case Move:
+		return "move"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Change は 1 つの編集操作を表す
+// Insert と Delete は挿入、削除された部分木のルートだけを報告する
+type Change struct {
+	Kind Kind
+	// 変更前のノード
+	// Insert のときは nil
+	Old ast.Node
+	// 変更後のノード
+	// Delete のときは nil
+	New ast.Node
+}
+
+// Diff は old を new に変換する編集操作を返す
+// 結果は Delete が old の行きがけ順で先に並び、残りが new の行きがけ順で並ぶ
+func Diff(old, new ast.Node) []Change {
+	src, dst := newTree(old), newTree(new)
+	m := match(src, dst)
+	moved := movedNodes(m, src)
+
+	var cs []Change
+	for _, s := range src.preorder() {
+		if m.hasSrc(s) {
+			continue
+		}
+		if s.parent == nil || m.hasSrc(s.parent) {
+			cs = append(cs, Change{Kind: Delete, Old: s.node})
+		}
+	}
+	for _, d := range dst.preorder() {
+		s, ok := m.dst[d]
+		if !ok {
+			if d.parent == nil || m.hasDst(d.parent) {
+				cs = append(cs, Change{Kind: Insert, New: d.node})
+			}
+			continue
+		}
+		if s.label == d.label && s.value != d.value {
+			cs = append(cs, Change{Kind: Update, Old: s.node, New: d.node})
+		}
+		if moved[s] {
+			cs = append(cs, Change{Kind: Move, Old: s.node, New: d.node})
+		}
+	}
+	return cs
+}
+
+// movedNodes は移動したノードを返す
+// 親の対応先が変わったノードと、兄弟の中で順番が入れ替わったノードが移動したとみなされる
+func movedNodes(m *mapping, src *tree) map[*tree]bool {
+	moved := map[*tree]bool{}
+	for _, s := range src.preorder() {
+		d, ok := m.src[s]
+		if !ok || s.parent == nil {
+			continue
+		}
+		if m.src[s.parent] != d.parent {
+			moved[s] = true
+		}
+	}
+
+	// 親同士が対応している子の中で、順番を保っている最大の集合に含まれないものを移動したとみなす
+	for _, s := range src.preorder() {
+		d, ok := m.src[s]
+		if !ok {
+			continue
+		}
+		index := map[*tree]int{}
+		for i, dc := range d.children {
+			index[dc] = i
+		}
+		var kept []*tree
+		var order []int
+		for _, sc := range s.children {
+			dc, ok := m.src[sc]
+			if !ok || dc.parent != d {
+				continue
+			}
+			kept = append(kept, sc)
+			order = append(order, index[dc])
+		}
+		inLIS := longestIncreasing(order)
+		for i, sc := range kept {
+			if !inLIS[i] {
+				moved[sc] = true
+			}
+		}
+	}
+	return moved
+}
+
+// longestIncreasing は xs の最長増加部分列に含まれる要素の添字を返す
+func longestIncreasing(xs []int) map[int]bool {
+	n := len(xs)
+	length := make([]int, n)
+	prev := make([]int, n)
+	best := -1
+	for i := 0; i < n; i++ {
+		length[i], prev[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if xs[j] < xs[i] && length[j]+1 > length[i] {
+				length[i], prev[i] = length[j]+1, j
+			}
+		}
+		if best < 0 || length[i] > length[best] {
+			best = i
+		}
+	}
+
+	in := map[int]bool{}
+	for i := best; i >= 0; i = prev[i] {
+		in[i] = true
+	}
+	return in
+}
+
+// Equal は a と b が位置を除いて同じ構造と値を持つときに true を返す
+// テストで String() の結果を比較する代わりに使える
+func Equal(a, b ast.Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return newTree(a).key == newTree(b).key
+}
+
+// Fprint は cs を 1 行に 1 つずつ w に出力する
+// oldFile と newFile は位置を 'line:col' に変換するために使う
+func Fprint(w io.Writer, cs []Change, oldFile, newFile *token.File) error {
+	for _, c := range cs {
+		var s string
+		switch c.Kind {
+		case Insert:
+			s = fmt.Sprintf("insert %s at %s: %s",
+				label(c.New), newFile.Position(c.New.Pos()), c.New)
+		case Delete:
+			s = fmt.Sprintf("delete %s at %s: %s",
+				label(c.Old), oldFile.Position(c.Old.Pos()), c.Old)
+		case Update:
+			s = fmt.Sprintf("update %s at %s: %s -> %s",
+				label(c.New), newFile.Position(c.New.Pos()), value(c.Old), value(c.New))
+		case Move:
+			s = fmt.Sprintf("move %s from %s to %s: %s",
+				label(c.New), oldFile.Position(c.Old.Pos()), newFile.Position(c.New.Pos()), c.New)
+		}
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
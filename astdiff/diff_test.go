@@ -0,0 +1,123 @@
+package astdiff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	prg := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parse %q: %v", input, errs)
+	}
+	return prg
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		expected []string
+	}{
+		{
+			"whitespace only",
+			"a + b * c;",
+			"a+b*c   ;\n",
+			nil,
+		},
+		{
+			"update literal",
+			"1 + 2;",
+			"1 + 3;",
+			[]string{"update IntegerLiteral 2 -> 3"},
+		},
+		{
+			"update operator",
+			"a + b * c;",
+			"a + b / c;",
+			[]string{"update InfixExpression * -> /"},
+		},
+		{
+			"insert statement",
+			"a + 1; b;",
+			"a + 1; c * 2; b;",
+			[]string{"insert ExpressionStatement (c * 2)"},
+		},
+		{
+			"delete statement",
+			"a + 1; c * 2; b;",
+			"a + 1; b;",
+			[]string{"delete ExpressionStatement (c * 2)"},
+		},
+		{
+			"move statement",
+			"a + 1; b * 2; c - 3;",
+			"c - 3; a + 1; b * 2;",
+			[]string{"move ExpressionStatement (c - 3)"},
+		},
+		{
+			"wrap in prefix",
+			"a + b * c;",
+			"a + -(b * c);",
+			[]string{
+				"insert PrefixExpression (-(b * c))",
+				"move InfixExpression (b * c)",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual []string
+			for _, ch := range Diff(parse(t, c.old), parse(t, c.new)) {
+				actual = append(actual, format(ch))
+			}
+			if fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+				t.Fatalf("want Diff() = %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func format(c Change) string {
+	switch c.Kind {
+	case Insert:
+		return fmt.Sprintf("%s %s %s", c.Kind, label(c.New), c.New)
+	case Delete:
+		return fmt.Sprintf("%s %s %s", c.Kind, label(c.Old), c.Old)
+	case Update:
+		return fmt.Sprintf("%s %s %s -> %s", c.Kind, label(c.New), value(c.Old), value(c.New))
+	default:
+		return fmt.Sprintf("%s %s %s", c.Kind, label(c.New), c.New)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"let x = 1;", "let   x=1 ;", true},
+		{"(a + b) * c", "(a + b) * c;", true},
+		{"(a + b) * c", "a + b * c", false},
+		{"a == b", "a != b", false},
+		{"x", "y", false},
+	}
+
+	for _, c := range cases {
+		if actual := Equal(parse(t, c.a), parse(t, c.b)); actual != c.expected {
+			t.Errorf("want Equal(%q, %q) = %t, got %t", c.a, c.b, c.expected, actual)
+		}
+	}
+}
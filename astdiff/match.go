@@ -0,0 +1,218 @@
+package astdiff
+
+import "sort"
+
+const (
+	// top-down で同形の部分木として対応させる最小の高さ
+	// 葉は同じ値が何度も現れるので、親が対応した後に対応させる
+	minHeight = 2
+	// bottom-up で内部ノードを対応させる dice 係数のしきい値
+	minDice = 0.5
+)
+
+// mapping は 2 つの木のノードの対応を保持する
+type mapping struct {
+	src map[*tree]*tree
+	dst map[*tree]*tree
+}
+
+func newMapping() *mapping {
+	return &mapping{src: map[*tree]*tree{}, dst: map[*tree]*tree{}}
+}
+
+func (m *mapping) add(s, d *tree) {
+	m.src[s] = d
+	m.dst[d] = s
+}
+
+func (m *mapping) hasSrc(s *tree) bool {
+	_, ok := m.src[s]
+	return ok
+}
+
+func (m *mapping) hasDst(d *tree) bool {
+	_, ok := m.dst[d]
+	return ok
+}
+
+// addSubtree は同形の部分木のノードを順番に対応させる
+// すでに対応しているノードはそのままにする
+func (m *mapping) addSubtree(s, d *tree) {
+	ss, ds := s.preorder(), d.preorder()
+	for i := range ss {
+		if m.hasSrc(ss[i]) || m.hasDst(ds[i]) {
+			continue
+		}
+		m.add(ss[i], ds[i])
+	}
+}
+
+// match は GumTree のアルゴリズムを簡略化したもので、2 つの木のノードを対応させる
+// 1. top-down: 高い部分木から順に、同形の部分木を対応させる
+// 2. bottom-up: 子孫の対応が多い内部ノードを対応させ、その子を改めて対応させる
+func match(src, dst *tree) *mapping {
+	m := newMapping()
+	matchTopDown(m, src, dst)
+	matchBottomUp(m, src, dst)
+	return m
+}
+
+func matchTopDown(m *mapping, src, dst *tree) {
+	byKey := func(root *tree) map[string][]*tree {
+		ts := map[string][]*tree{}
+		for _, t := range root.preorder() {
+			if t.height >= minHeight {
+				ts[t.key] = append(ts[t.key], t)
+			}
+		}
+		return ts
+	}
+	srcKeys, dstKeys := byKey(src), byKey(dst)
+
+	// 高い部分木から対応させる
+	// 高さが同じときは行きがけ順にして結果を決定的にする
+	candidates := src.preorder()
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].height > candidates[j].height
+	})
+
+	for _, s := range candidates {
+		if s.height < minHeight || m.hasSrc(s) {
+			continue
+		}
+		ds := dstKeys[s.key]
+		// 同形の候補が複数あるときは、出現順が同じものを対応させる
+		best := nthUnmatched(m, srcKeys[s.key], ds, s)
+		if best == nil {
+			continue
+		}
+		m.addSubtree(s, best)
+	}
+}
+
+// nthUnmatched は ss の中での s の出現順と同じ順番の、まだ対応していない ds の要素を返す
+// そのような要素が無いときは、まだ対応していない最後の要素を返す
+func nthUnmatched(m *mapping, ss, ds []*tree, s *tree) *tree {
+	n := 0
+	for _, t := range ss {
+		if t == s {
+			break
+		}
+		if !m.hasSrc(t) {
+			n++
+		}
+	}
+	var last *tree
+	for _, d := range ds {
+		if m.hasDst(d) {
+			continue
+		}
+		if n == 0 {
+			return d
+		}
+		n--
+		last = d
+	}
+	return last
+}
+
+func matchBottomUp(m *mapping, src, dst *tree) {
+	// ルート同士は常に対応させる
+	if !m.hasSrc(src) && !m.hasDst(dst) {
+		m.add(src, dst)
+	}
+
+	for _, s := range src.postorder() {
+		if s.isLeaf() {
+			continue
+		}
+		if m.hasSrc(s) {
+			recoverChildren(m, s, m.src[s])
+			continue
+		}
+		d := bestCandidate(m, s)
+		if d == nil {
+			continue
+		}
+		m.add(s, d)
+		recoverChildren(m, s, d)
+	}
+}
+
+// bestCandidate は s の子孫と対応している dst のノードの祖先のうち、
+// s と種類が同じで dice 係数が最も大きいものを返す
+func bestCandidate(m *mapping, s *tree) *tree {
+	var best *tree
+	bestDice := 0.0
+	seen := map[*tree]bool{}
+	for _, c := range s.descendants() {
+		d, ok := m.src[c]
+		if !ok {
+			continue
+		}
+		for a := d.parent; a != nil; a = a.parent {
+			if seen[a] {
+				continue
+			}
+			seen[a] = true
+			if a.label != s.label || m.hasDst(a) {
+				continue
+			}
+			if v := dice(m, s, a); v > bestDice {
+				best, bestDice = a, v
+			}
+		}
+	}
+	if bestDice < minDice {
+		return nil
+	}
+	return best
+}
+
+// dice は s と d の子孫のうち、互いに対応しているものの割合を返す
+func dice(m *mapping, s, d *tree) float64 {
+	sd, dd := s.descendants(), d.descendants()
+	if len(sd)+len(dd) == 0 {
+		return 0
+	}
+	in := map[*tree]bool{}
+	for _, t := range dd {
+		in[t] = true
+	}
+	common := 0
+	for _, t := range sd {
+		if x, ok := m.src[t]; ok && in[x] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(sd)+len(dd))
+}
+
+// recoverChildren は対応した s と d の子のうち、まだ対応していないものを対応させる
+// 同形のもの、種類と値が同じもの、種類が同じものの順に対応させる
+func recoverChildren(m *mapping, s, d *tree) {
+	same := []func(a, b *tree) bool{
+		func(a, b *tree) bool { return a.key == b.key },
+		func(a, b *tree) bool { return a.label == b.label && a.value == b.value },
+		func(a, b *tree) bool { return a.label == b.label },
+	}
+	for _, eq := range same {
+		for _, sc := range s.children {
+			if m.hasSrc(sc) {
+				continue
+			}
+			for _, dc := range d.children {
+				if m.hasDst(dc) || !eq(sc, dc) {
+					continue
+				}
+				if sc.key == dc.key {
+					m.addSubtree(sc, dc)
+				} else {
+					m.add(sc, dc)
+					recoverChildren(m, sc, dc)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package astdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hiroygo/go-interpreter/ast"
+)
+
+// tree は比較のために ast.Node を包んだもの
+// 位置の情報は比較に使わない
+type tree struct {
+	node     ast.Node
+	label    string
+	value    string
+	parent   *tree
+	children []*tree
+	// 葉の高さが 1 になる
+	height int
+	// 部分木の構造と値をすべて含む文字列
+	// key が同じ部分木は同形になる
+	key string
+}
+
+func newTree(n ast.Node) *tree {
+	return build(n, nil)
+}
+
+func build(n ast.Node, parent *tree) *tree {
+	t := &tree{
+		node:   n,
+		label:  label(n),
+		value:  value(n),
+		parent: parent,
+	}
+	for _, c := range children(n) {
+		t.children = append(t.children, build(c, t))
+	}
+
+	var b strings.Builder
+	b.WriteString(t.label)
+	if t.value != "" {
+		b.WriteString(":" + t.value)
+	}
+	t.height = 1
+	if len(t.children) > 0 {
+		b.WriteString("(")
+		for i, c := range t.children {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(c.key)
+			if c.height+1 > t.height {
+				t.height = c.height + 1
+			}
+		}
+		b.WriteString(")")
+	}
+	t.key = b.String()
+	return t
+}
+
+// children は n の直接の子ノードを順番に返す
+func children(n ast.Node) []ast.Node {
+	var cs []ast.Node
+	ast.Inspect(n, func(c ast.Node) bool {
+		if c == nil {
+			return false
+		}
+		if c == n {
+			return true
+		}
+		cs = append(cs, c)
+		return false
+	})
+	return cs
+}
+
+func label(n ast.Node) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+}
+
+// value はノードの種類以外で比較に使う値を返す
+// 値が異なるノードの対応は Update として報告される
+func value(n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.Identifier:
+		return n.Value
+	case *ast.IntegerLiteral:
+		return n.Token.Literal
+	case *ast.Boolean:
+		return n.Token.Literal
+	case *ast.PrefixExpression:
+		return n.Operator
+	case *ast.InfixExpression:
+		return n.Operator
+	}
+	return ""
+}
+
+// preorder は行きがけ順でノードを返す
+func (t *tree) preorder() []*tree {
+	ts := []*tree{t}
+	for _, c := range t.children {
+		ts = append(ts, c.preorder()...)
+	}
+	return ts
+}
+
+// postorder は帰りがけ順でノードを返す
+func (t *tree) postorder() []*tree {
+	var ts []*tree
+	for _, c := range t.children {
+		ts = append(ts, c.postorder()...)
+	}
+	return append(ts, t)
+}
+
+// descendants は t 自身を含まない子孫を返す
+func (t *tree) descendants() []*tree {
+	return t.preorder()[1:]
+}
+
+func (t *tree) isLeaf() bool {
+	return len(t.children) == 0
+}
@@ -0,0 +1,235 @@
+// astgen は構文として正しい ast.Program をランダムに作る
+// 作ったプログラムを文字列にして構文解析し直すことで、パーサーや String() の誤りを探す
+package astgen
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/astdiff"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Config は作るプログラムの大きさを決める
+type Config struct {
+	// 式の木の深さの上限
+	// 0 のときは 4
+	MaxDepth int
+	// 文の数の上限
+	// 0 のときは 5
+	MaxStatements int
+	// 識別子に使う名前
+	// nil のときは DefaultNames
+	Names []string
+}
+
+// DefaultNames は識別子に使う既定の名前
+// キーワードの綴り誤りに見える名前も含める
+var DefaultNames = []string{"a", "b", "c", "f", "i", "x", "y", "total", "count", "value", "retrun"}
+
+var (
+	prefixOperators = []token.TokenType{token.BANG, token.MINUS}
+	infixOperators  = []token.TokenType{
+		token.PLUS, token.MINUS, token.ASTERISK, token.SLASH,
+		token.LT, token.GT, token.EQ, token.NOT_EQ,
+	}
+)
+
+// Generator はランダムなプログラムを作る
+type Generator struct {
+	r   *rand.Rand
+	cfg Config
+}
+
+func New(r *rand.Rand, cfg Config) *Generator {
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = 4
+	}
+	if cfg.MaxStatements <= 0 {
+		cfg.MaxStatements = 5
+	}
+	if cfg.Names == nil {
+		cfg.Names = DefaultNames
+	}
+	return &Generator{r: r, cfg: cfg}
+}
+
+// Program は 1 個以上 MaxStatements 個以下の文からなるプログラムを返す
+func (g *Generator) Program() *ast.Program {
+	prg := &ast.Program{}
+	n := 1 + g.r.Intn(g.cfg.MaxStatements)
+	for i := 0; i < n; i++ {
+		prg.Statements = append(prg.Statements, g.Statement())
+	}
+	return prg
+}
+
+// Statement はすべての種類の文から 1 つを選んで返す
+func (g *Generator) Statement() ast.Statement {
+	switch g.r.Intn(3) {
+	case 0:
+		return &ast.LetStatement{
+			Token: keyword(token.LET),
+			Name:  g.identifier(),
+			Value: g.Expression(g.cfg.MaxDepth),
+		}
+	case 1:
+		return &ast.ReturnStatement{
+			Token:       keyword(token.RETURN),
+			ReturnValue: g.Expression(g.cfg.MaxDepth),
+		}
+	}
+	e := g.Expression(g.cfg.MaxDepth)
+	return &ast.ExpressionStatement{Token: firstToken(e), Expression: e}
+}
+
+// Expression は深さが depth 以下の式を返す
+// depth が 1 以下のときは識別子やリテラルを返す
+func (g *Generator) Expression(depth int) ast.Expression {
+	if depth <= 1 || g.r.Intn(4) == 0 {
+		return g.leaf()
+	}
+
+	switch g.r.Intn(3) {
+	case 0:
+		op := prefixOperators[g.r.Intn(len(prefixOperators))]
+		return &ast.PrefixExpression{
+			Token:    operator(op),
+			Operator: op.String(),
+			Right:    g.Expression(depth - 1),
+		}
+	case 1:
+		op := infixOperators[g.r.Intn(len(infixOperators))]
+		return &ast.InfixExpression{
+			Token:    operator(op),
+			Left:     g.Expression(depth - 1),
+			Operator: op.String(),
+			Right:    g.Expression(depth - 1),
+		}
+	}
+	return &ast.GroupedExpression{
+		Token:      operator(token.LPAREN),
+		Expression: g.Expression(depth - 1),
+	}
+}
+
+func (g *Generator) leaf() ast.Expression {
+	switch g.r.Intn(3) {
+	case 0:
+		return g.identifier()
+	case 1:
+		return integer(int64(g.r.Intn(1000)))
+	}
+	return boolean(g.r.Intn(2) == 0)
+}
+
+func (g *Generator) identifier() *ast.Identifier {
+	name := g.cfg.Names[g.r.Intn(len(g.cfg.Names))]
+	return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+}
+
+func keyword(t token.TokenType) token.Token {
+	w, _ := token.Standard.Keyword(t)
+	return token.Token{Type: t, Literal: w}
+}
+
+// operator は記号のトークンを返す
+// 記号のトークンは種類とリテラルが同じ
+func operator(t token.TokenType) token.Token {
+	return token.Token{Type: t, Literal: t.String()}
+}
+
+func integer(v int64) *ast.IntegerLiteral {
+	return &ast.IntegerLiteral{
+		Token: token.Token{Type: token.INT, Literal: strconv.FormatInt(v, 10)},
+		Value: v,
+	}
+}
+
+func boolean(v bool) *ast.Boolean {
+	t := keyword(token.FALSE)
+	if v {
+		t = keyword(token.TRUE)
+	}
+	return &ast.Boolean{Token: t, Value: v}
+}
+
+// firstToken は e を文字列にしたときの最初のトークンを返す
+// String() は前置式と中置式を括弧で囲む
+func firstToken(e ast.Expression) token.Token {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		return e.Token
+	case *ast.IntegerLiteral:
+		return e.Token
+	case *ast.Boolean:
+		return e.Token
+	case *ast.GroupedExpression:
+		return firstToken(e.Expression)
+	}
+	return operator(token.LPAREN)
+}
+
+// Render は prg を構文解析できる文字列にする
+// Program.String() は式文の ';' を省くので、式文の後に ';' を補い、文ごとに改行する
+func Render(prg *ast.Program) string {
+	var b strings.Builder
+	for _, s := range prg.Statements {
+		b.WriteString(s.String())
+		if _, ok := s.(*ast.ExpressionStatement); ok {
+			b.WriteString(";")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Equivalent は a と b が括弧の有無を除いて同じプログラムのときに true を返す
+// 括弧を取り除いた木を astdiff で比べるので、位置やトークンの綴りの違いは無視する
+func Equivalent(a, b *ast.Program) bool {
+	return astdiff.Equal(unparen(a), unparen(b))
+}
+
+// unparen は GroupedExpression を取り除いた prg の複製を返す
+// String() は前置式と中置式を括弧で囲むので、構文解析し直すと GroupedExpression が増えるため
+func unparen(prg *ast.Program) *ast.Program {
+	out := &ast.Program{Statements: make([]ast.Statement, len(prg.Statements))}
+	for i, s := range prg.Statements {
+		switch s := s.(type) {
+		case *ast.LetStatement:
+			c := *s
+			c.Value = unparenExpr(s.Value)
+			out.Statements[i] = &c
+		case *ast.ReturnStatement:
+			c := *s
+			c.ReturnValue = unparenExpr(s.ReturnValue)
+			out.Statements[i] = &c
+		case *ast.ExpressionStatement:
+			c := *s
+			c.Expression = unparenExpr(s.Expression)
+			out.Statements[i] = &c
+		default:
+			out.Statements[i] = s
+		}
+	}
+	return out
+}
+
+func unparenExpr(e ast.Expression) ast.Expression {
+	switch e := e.(type) {
+	case *ast.GroupedExpression:
+		return unparenExpr(e.Expression)
+	case *ast.PrefixExpression:
+		c := *e
+		c.Right = unparenExpr(e.Right)
+		return &c
+	case *ast.InfixExpression:
+		c := *e
+		c.Left = unparenExpr(e.Left)
+		c.Right = unparenExpr(e.Right)
+		return &c
+	}
+	return e
+}
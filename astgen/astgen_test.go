@@ -0,0 +1,119 @@
+package astgen
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+func TestRoundTrip(t *testing.T) {
+	Check(t, 1, 1000, Config{}, RoundTrip)
+	Check(t, 2, 200, Config{MaxDepth: 8, MaxStatements: 10}, RoundTrip)
+}
+
+func TestEquivalent(t *testing.T) {
+	name := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "1"}, Value: "1"}
+	cases := []struct {
+		name     string
+		a, b     *ast.Program
+		expected bool
+	}{
+		{
+			"parens are ignored",
+			&ast.Program{Statements: []ast.Statement{&ast.ExpressionStatement{Expression: integer(1)}}},
+			&ast.Program{Statements: []ast.Statement{&ast.ExpressionStatement{
+				Expression: &ast.GroupedExpression{Expression: integer(1)},
+			}}},
+			true,
+		},
+		{
+			// String() はどちらも '1' になるが、木の構造は違う
+			"same string, different node",
+			&ast.Program{Statements: []ast.Statement{&ast.ExpressionStatement{Expression: integer(1)}}},
+			&ast.Program{Statements: []ast.Statement{&ast.ExpressionStatement{Expression: name}}},
+			false,
+		},
+	}
+	for _, c := range cases {
+		if actual := Equivalent(c.a, c.b); actual != c.expected {
+			t.Errorf("%s: want Equivalent() = %t, got %t", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestGeneratorCoversAllNodes(t *testing.T) {
+	g := New(rand.New(rand.NewSource(1)), Config{})
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		ast.Inspect(g.Program(), func(n ast.Node) bool {
+			if n != nil {
+				seen[fmt.Sprintf("%T", n)] = true
+			}
+			return true
+		})
+	}
+	kinds := []string{
+		"*ast.Program", "*ast.LetStatement", "*ast.ReturnStatement", "*ast.ExpressionStatement",
+		"*ast.Identifier", "*ast.IntegerLiteral", "*ast.Boolean",
+		"*ast.PrefixExpression", "*ast.InfixExpression", "*ast.GroupedExpression",
+	}
+	for _, k := range kinds {
+		if !seen[k] {
+			t.Errorf("want the generator to produce %s", k)
+		}
+	}
+}
+
+func TestDepth(t *testing.T) {
+	g := New(rand.New(rand.NewSource(1)), Config{MaxDepth: 3})
+	for i := 0; i < 200; i++ {
+		if d := depth(g.Expression(3)); d > 3 {
+			t.Fatalf("want depth <= 3, got %d", d)
+		}
+	}
+}
+
+func TestShrink(t *testing.T) {
+	// '/' を含むプログラムを失敗とみなすと、最小のプログラムは '(0 / 0)' になる
+	hasDivision := func(prg *ast.Program) error {
+		found := false
+		ast.Inspect(prg, func(n ast.Node) bool {
+			if in, ok := n.(*ast.InfixExpression); ok && in.Operator == "/" {
+				found = true
+			}
+			return true
+		})
+		if found {
+			return errors.New("division")
+		}
+		return nil
+	}
+
+	g := New(rand.New(rand.NewSource(3)), Config{MaxDepth: 6, MaxStatements: 8})
+	for i := 0; i < 100; i++ {
+		prg := g.Program()
+		if hasDivision(prg) == nil {
+			continue
+		}
+		minimal := Shrink(prg, func(p *ast.Program) bool { return hasDivision(p) != nil })
+		if Render(minimal) != "(0 / 0);\n" {
+			t.Fatalf("want a minimal program, got %q", Render(minimal))
+		}
+	}
+}
+
+func depth(e ast.Expression) int {
+	switch e := e.(type) {
+	case *ast.PrefixExpression:
+		return 1 + depth(e.Right)
+	case *ast.InfixExpression:
+		return 1 + max(depth(e.Left), depth(e.Right))
+	case *ast.GroupedExpression:
+		return 1 + depth(e.Expression)
+	}
+	return 1
+}
@@ -0,0 +1,162 @@
+package astgen
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/parser"
+)
+
+// Shrink は fails が true を返したままになるように prg を小さくして返す
+// 文を消す、文を式文にする、式をその部分式や 0 に置き換える、を変化が無くなるまで繰り返す
+// prg は書き換えられる
+func Shrink(prg *ast.Program, fails func(*ast.Program) bool) *ast.Program {
+	for shrinkOnce(prg, fails) {
+	}
+	return prg
+}
+
+// shrinkOnce は小さくできたときに true を返す
+func shrinkOnce(prg *ast.Program, fails func(*ast.Program) bool) bool {
+	// 文を消す
+	for i := range prg.Statements {
+		if len(prg.Statements) == 1 {
+			break
+		}
+		orig := prg.Statements
+		prg.Statements = append(append([]ast.Statement{}, orig[:i]...), orig[i+1:]...)
+		if fails(prg) {
+			return true
+		}
+		prg.Statements = orig
+	}
+
+	// let 文と return 文を式文に置き換える
+	for i, s := range prg.Statements {
+		var e ast.Expression
+		switch s := s.(type) {
+		case *ast.LetStatement:
+			e = s.Value
+		case *ast.ReturnStatement:
+			e = s.ReturnValue
+		default:
+			continue
+		}
+		prg.Statements[i] = &ast.ExpressionStatement{Token: firstToken(e), Expression: e}
+		if fails(prg) {
+			return true
+		}
+		prg.Statements[i] = s
+	}
+
+	// 式を小さな式に置き換える
+	for _, s := range prg.Statements {
+		for _, slot := range slots(s) {
+			orig := *slot
+			for _, c := range candidates(orig) {
+				*slot = c
+				syncToken(s)
+				if fails(prg) {
+					return true
+				}
+			}
+			*slot = orig
+			syncToken(s)
+		}
+	}
+	return false
+}
+
+// slots は s の中で式を置き換えられる場所を、外側から順に返す
+func slots(s ast.Statement) []*ast.Expression {
+	var ss []*ast.Expression
+	var walk func(p *ast.Expression)
+	walk = func(p *ast.Expression) {
+		ss = append(ss, p)
+		switch e := (*p).(type) {
+		case *ast.PrefixExpression:
+			walk(&e.Right)
+		case *ast.InfixExpression:
+			walk(&e.Left)
+			walk(&e.Right)
+		case *ast.GroupedExpression:
+			walk(&e.Expression)
+		}
+	}
+
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		walk(&s.Value)
+	case *ast.ReturnStatement:
+		walk(&s.ReturnValue)
+	case *ast.ExpressionStatement:
+		walk(&s.Expression)
+	}
+	return ss
+}
+
+// candidates は e より小さい式を、小さい順に返す
+// 一番小さい式は 0 とする
+func candidates(e ast.Expression) []ast.Expression {
+	var cs []ast.Expression
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		if e.Value != 0 {
+			cs = append(cs, integer(0))
+		}
+		return cs
+	case *ast.Identifier, *ast.Boolean:
+		return []ast.Expression{integer(0)}
+	case *ast.PrefixExpression:
+		cs = append(cs, e.Right)
+	case *ast.InfixExpression:
+		cs = append(cs, e.Left, e.Right)
+	case *ast.GroupedExpression:
+		cs = append(cs, e.Expression)
+	}
+	return append([]ast.Expression{integer(0)}, cs...)
+}
+
+// syncToken は式文の Token を式の最初のトークンに合わせる
+func syncToken(s ast.Statement) {
+	if es, ok := s.(*ast.ExpressionStatement); ok {
+		es.Token = firstToken(es.Expression)
+	}
+}
+
+// RoundTrip は prg を Render した文字列を構文解析し、同じプログラムに戻ることを確かめる
+// 比べるのは木の構造で、文字列はエラーで再現手順として表示するためだけに使う
+func RoundTrip(prg *ast.Program) error {
+	src := Render(prg)
+	p := parser.New(lexer.New(src))
+	got := p.ParseProgram()
+	if err := p.ErrorList().Err(); err != nil {
+		return fmt.Errorf("parse %q: %w", src, err)
+	}
+	if !Equivalent(prg, got) {
+		return fmt.Errorf("parse %q: got %q", src, Render(got))
+	}
+	return nil
+}
+
+// Check は seed から n 個のプログラムを作り、それぞれ prop を確かめる
+// prop がエラーを返したときは、Shrink したプログラムと一緒に t に報告する
+// 他のパッケージの性質のテストから使う
+// e.g. 'astgen.Check(t, 1, 500, astgen.Config{}, astgen.RoundTrip)'
+func Check(t testing.TB, seed int64, n int, cfg Config, prop func(*ast.Program) error) {
+	t.Helper()
+
+	g := New(rand.New(rand.NewSource(seed)), cfg)
+	for i := 0; i < n; i++ {
+		prg := g.Program()
+		if err := prop(prg); err != nil {
+			minimal := Shrink(prg, func(p *ast.Program) bool {
+				return prop(p) != nil
+			})
+			t.Fatalf("seed %d, program %d: %v\nminimal program:\n%s\nerror: %v", seed, i, err, Render(minimal), prop(minimal))
+		}
+	}
+}
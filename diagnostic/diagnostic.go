@@ -0,0 +1,253 @@
+// diagnostic はエラーの位置をソースの行と一緒に表示する
+//
+//	error[E0001]: expected next token to be "=", got "INT" instead
+//	 --> script.mk:2:7
+//	  |
+//	2 | let y 2;
+//	  |       ^
+//	  = help: ...
+package diagnostic
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/object"
+	"github.com/hiroygo/go-interpreter/parser"
+	"github.com/hiroygo/go-interpreter/resolver"
+	"github.com/hiroygo/go-interpreter/suggest"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Diagnostic は表示する 1 つのエラーや警告を表す
+type Diagnostic struct {
+	// e.g. 'error', 'warning'
+	Severity string
+	// エラーコード
+	// 空ではないときは 'error[E0003]:' のように表示する
+	Code string
+	// 下線を引く範囲
+	// End が Pos 以前のときは Pos の 1 文字に引く
+	Pos     token.Pos
+	End     token.Pos
+	Message string
+	// 補足の説明
+	Notes []string
+	// 直し方の提案
+	Help []string
+}
+
+// FromParserError は構文エラーを Diagnostic に変換する
+// 名前の候補は help として表示する
+func FromParserError(e *parser.Error) Diagnostic {
+	d := Diagnostic{
+		Severity: "error",
+		Code:     string(e.Code),
+		Pos:      e.Pos,
+		End:      e.End,
+		Message:  e.Msg,
+		Notes:    e.Notes,
+		Help:     e.Help,
+	}
+	d.Help = appendHints(d.Help, e.Lang, e.Hints)
+	return d
+}
+
+// FromLexerError は字句解析のエラーを Diagnostic に変換する
+// 構文エラーと同じコードで表示する
+func FromLexerError(e *lexer.Error) Diagnostic {
+	return Diagnostic{
+		Severity: "error",
+		Code:     string(e.Code),
+		Pos:      e.Pos,
+		End:      e.End,
+		Message:  e.Msg,
+	}
+}
+
+// FromResolverDiagnostic は resolver の診断を Diagnostic に変換する
+// 名前の候補は FromParserError と同じように lang の help として表示する
+func FromResolverDiagnostic(d resolver.Diagnostic, lang parser.Language) Diagnostic {
+	return Diagnostic{
+		Severity: d.Severity.String(),
+		Pos:      d.Pos,
+		End:      d.End,
+		Message:  d.Msg,
+		Help:     appendHints(nil, lang, d.Hints),
+	}
+}
+
+// FromUndefined は評価中に名前が見つからなかったエラー e を、resolver の診断に置き換える
+// globals は評価に使った環境で束縛されていた名前で、候補にも使う
+// e が未定義の名前のエラーではないときは false を返す
+func FromUndefined(prg *ast.Program, globals []string, e *object.Error, lang parser.Language) (Diagnostic, bool) {
+	for _, d := range resolver.Resolve(prg, globals).Diagnostics {
+		// 実行時のエラーの位置は、識別子のときだけ識別子の先頭になる
+		if d.Severity == resolver.Error && d.Pos == e.Pos {
+			return FromResolverDiagnostic(d, lang), true
+		}
+	}
+	return Diagnostic{}, false
+}
+
+func appendHints(help []string, lang parser.Language, hints []suggest.Hint) []string {
+	for _, h := range hints {
+		help = append(help, parser.HintMessage(lang, h))
+	}
+	return help
+}
+
+// Renderer は Diagnostic をソースの抜粋付きで出力する
+type Renderer struct {
+	// ANSI エスケープシーケンスで色を付ける
+	Color bool
+}
+
+// NewRenderer は w が端末のときだけ色を付ける Renderer を返す
+func NewRenderer(w io.Writer) *Renderer {
+	return &Renderer{Color: IsTerminal(w)}
+}
+
+// IsTerminal は w が端末につながっているときに true を返す
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// 色ごとのエスケープシーケンス
+const (
+	colorReset   = "\x1b[0m"
+	colorBold    = "\x1b[1m"
+	colorError   = "\x1b[1;31m"
+	colorWarning = "\x1b[1;33m"
+	colorGutter  = "\x1b[1;34m"
+	colorHelp    = "\x1b[1;36m"
+)
+
+func (r *Renderer) paint(color, s string) string {
+	if !r.Color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (r *Renderer) severityColor(sev string) string {
+	if sev == "warning" {
+		return colorWarning
+	}
+	return colorError
+}
+
+// Render は src の中の d を w に出力する
+// file は src から作ったもので、位置とファイル名の表示に使う
+func (r *Renderer) Render(w io.Writer, file *token.File, src string, d Diagnostic) error {
+	bw := bufio.NewWriter(w)
+	sevColor := r.severityColor(d.Severity)
+
+	label := d.Severity
+	if d.Code != "" {
+		label += "[" + d.Code + "]"
+	}
+	fmt.Fprintf(bw, "%s%s\n", r.paint(sevColor, label+":"), r.paint(colorBold, " "+d.Message))
+
+	pos := file.Position(d.Pos)
+	lineNum := strconv.Itoa(pos.Line)
+	gutter := strings.Repeat(" ", len(lineNum))
+	fmt.Fprintf(bw, "%s%s %s\n", gutter, r.paint(colorGutter, "-->"), pos)
+
+	if pos.IsValid() {
+		line := lineText(src, file.LineStart(pos.Line))
+		start := pos.Column - 1
+		if start > len(line) {
+			start = len(line)
+		}
+		// 複数行にまたがる範囲は、最初の行の終わりまで下線を引く
+		end := start + 1
+		if d.End > d.Pos {
+			end = start + (d.End.Offset() - d.Pos.Offset())
+		}
+		if end > len(line) {
+			end = len(line)
+		}
+
+		fmt.Fprintf(bw, "%s %s\n", gutter, r.paint(colorGutter, "|"))
+		fmt.Fprintf(bw, "%s %s\n", r.paint(colorGutter, lineNum+" |"), line)
+		fmt.Fprintf(bw, "%s %s%s\n", gutter, r.paint(colorGutter, "|"),
+			" "+padding(line[:start])+r.paint(sevColor, carets(line[start:end])))
+	}
+
+	for _, n := range d.Notes {
+		fmt.Fprintf(bw, "%s %s note: %s\n", gutter, r.paint(colorGutter, "="), n)
+	}
+	for _, h := range d.Help {
+		fmt.Fprintf(bw, "%s %s %s %s\n", gutter, r.paint(colorGutter, "="), r.paint(colorHelp, "help:"), h)
+	}
+	return bw.Flush()
+}
+
+// RenderParserErrors は構文エラーをすべて w に出力する
+func (r *Renderer) RenderParserErrors(w io.Writer, file *token.File, src string, errs parser.ErrorList) error {
+	for _, e := range errs {
+		if err := r.Render(w, file, src, FromParserError(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderLexerErrors は字句解析のエラーをすべて w に出力する
+func (r *Renderer) RenderLexerErrors(w io.Writer, file *token.File, src string, errs lexer.ErrorList) error {
+	for _, e := range errs {
+		if err := r.Render(w, file, src, FromLexerError(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineText は start から始まる行のテキストを改行を除いて返す
+func lineText(src string, start int) string {
+	end := strings.IndexByte(src[start:], '\n')
+	if end < 0 {
+		return src[start:]
+	}
+	return strings.TrimSuffix(src[start:start+end], "\r")
+}
+
+// padding は prefix と同じ幅の空白を返す
+// タブはそのまま残して、表示上の位置を合わせる
+func padding(prefix string) string {
+	var b strings.Builder
+	for _, c := range prefix {
+		if c == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
+
+// carets は text の文字数だけ '^' を返す
+// 空のときは行末を指すように 1 つだけ返す
+func carets(text string) string {
+	n := utf8.RuneCountInString(text)
+	if n == 0 {
+		n = 1
+	}
+	return strings.Repeat("^", n)
+}
@@ -0,0 +1,151 @@
+package diagnostic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/object"
+	"github.com/hiroygo/go-interpreter/parser"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		d        Diagnostic
+		expected string
+	}{
+		{
+			"token span",
+			"let x = 1;\nlet y = foo + 2;\n",
+			Diagnostic{Severity: "error", Pos: 20, End: 23, Message: "undefined: foo"},
+			"error: undefined: foo\n" +
+				" --> a.mk:2:9\n" +
+				"  |\n" +
+				"2 | let y = foo + 2;\n" +
+				"  |         ^^^\n",
+		},
+		{
+			"tabs and notes",
+			"\tx é\n",
+			Diagnostic{Severity: "warning", Pos: 4, End: 6, Message: "bad", Notes: []string{"a note"}, Help: []string{"a help"}},
+			"warning: bad\n" +
+				" --> a.mk:1:4\n" +
+				"  |\n" +
+				"1 | \tx é\n" +
+				"  | \t  ^\n" +
+				"  = note: a note\n" +
+				"  = help: a help\n",
+		},
+		{
+			// EOF のように幅が無いときは行末を指す
+			"end of file",
+			"1 +",
+			Diagnostic{Severity: "error", Pos: 4, End: 4, Message: "eof"},
+			"error: eof\n" +
+				" --> a.mk:1:4\n" +
+				"  |\n" +
+				"1 | 1 +\n" +
+				"  |    ^\n",
+		},
+		{
+			"multiline span",
+			"(1 +\n2)",
+			Diagnostic{Severity: "error", Pos: 1, End: 8, Message: "group"},
+			"error: group\n" +
+				" --> a.mk:1:1\n" +
+				"  |\n" +
+				"1 | (1 +\n" +
+				"  | ^^^^\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := (&Renderer{}).Render(&b, token.NewFile("a.mk", c.src), c.src, c.d); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != c.expected {
+				t.Fatalf("want\n%s\ngot\n%s", c.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestRenderParserErrors(t *testing.T) {
+	src := "let x 5;"
+	p := parser.New(lexer.New(src))
+	p.ParseProgram()
+
+	var b bytes.Buffer
+	r := &Renderer{Color: true}
+	if err := r.RenderParserErrors(&b, token.NewFile("", src), src, p.ErrorList()); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\x1b[1;31merror[E0001]:\x1b[0m\x1b[1m expected next token to be \"=\", got \"INT\" instead\x1b[0m\n" +
+		" \x1b[1;34m-->\x1b[0m 1:7\n" +
+		"  \x1b[1;34m|\x1b[0m\n" +
+		"\x1b[1;34m1 |\x1b[0m let x 5;\n" +
+		"  \x1b[1;34m|\x1b[0m       \x1b[1;31m^\x1b[0m\n" +
+		"  \x1b[1;34m=\x1b[0m \x1b[1;36mhelp:\x1b[0m a let statement has the form 'let x = <expression>;'\n"
+	if b.String() != expected {
+		t.Fatalf("want %q, got %q", expected, b.String())
+	}
+}
+
+func TestRenderLexerErrors(t *testing.T) {
+	src := "let x = é;"
+	_, err := lexer.Tokenize(src, lexer.WithLanguage(parser.Japanese))
+	errs, ok := err.(lexer.ErrorList)
+	if !ok {
+		t.Fatalf("want lexer.ErrorList, got %v", err)
+	}
+
+	var b bytes.Buffer
+	if err := (&Renderer{}).RenderLexerErrors(&b, token.NewFile("a.mk", src), src, errs); err != nil {
+		t.Fatal(err)
+	}
+	expected := "error[E0004]: \"é\" は使えない文字です\n" +
+		" --> a.mk:1:9\n" +
+		"  |\n" +
+		"1 | let x = é;\n" +
+		"  |         ^\n"
+	if b.String() != expected {
+		t.Fatalf("want %q, got %q", expected, b.String())
+	}
+}
+
+func TestFromUndefined(t *testing.T) {
+	src := "let total = 1;\nretrun total + totl;"
+	p := parser.New(lexer.New(src))
+	prg := p.ParseProgram()
+
+	// 実行時のエラーと同じ位置にある未定義の名前の診断を使う
+	d, ok := FromUndefined(prg, []string{"argc"}, &object.Error{Pos: 16}, parser.English)
+	if !ok || d.Message != "undefined: retrun" || d.End != 22 {
+		t.Fatalf("want the diagnostic for retrun, got %+v, %t", d, ok)
+	}
+	if len(d.Help) != 1 || d.Help[0] != "unknown identifier `retrun`; did you mean keyword `return`?" {
+		t.Fatalf("want a keyword hint, got %q", d.Help)
+	}
+
+	d, ok = FromUndefined(prg, []string{"argc"}, &object.Error{Pos: 31}, parser.Japanese)
+	if !ok || len(d.Help) != 1 || !strings.Contains(d.Help[0], "total") {
+		t.Fatalf("want an identifier hint for totl, got %+v, %t", d, ok)
+	}
+
+	// 型の不一致など、名前以外のエラー
+	if _, ok := FromUndefined(prg, nil, &object.Error{Pos: 29}, parser.English); ok {
+		t.Fatal("want no diagnostic for an error that is not an undefined name")
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if IsTerminal(&bytes.Buffer{}) {
+		t.Fatal("want IsTerminal(*bytes.Buffer) = false")
+	}
+}
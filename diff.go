@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hiroygo/go-interpreter/astdiff"
+)
+
+// runDiff は 'diff old new' サブコマンドを実行し、終了コードを返す
+// diff コマンドと同じように、差分が無ければ 0、差分があれば 1、エラーのときは 2 を返す
+func runDiff(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) != 2 {
+		fmt.Fprintln(stderr, "usage: go-interpreter diff <old> <new>")
+		return exitUsage
+	}
+	// stdin は一度しか読めない
+	if args[0] == "-" && args[1] == "-" {
+		fmt.Fprintln(stderr, "diff: only one of <old> and <new> can be '-'")
+		return exitUsage
+	}
+
+	oldSrc, oldFile, err := readSource(args[0], stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	newSrc, newFile, err := readSource(args[1], stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	oldPrg, oldOK := parseSource(oldSrc, oldFile, stderr)
+	newPrg, newOK := parseSource(newSrc, newFile, stderr)
+	if !oldOK || !newOK {
+		return exitUsage
+	}
+
+	cs := astdiff.Diff(oldPrg, newPrg)
+	if err := astdiff.Fprint(stdout, cs, oldFile, newFile); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if len(cs) > 0 {
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,196 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/object"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// true, false, null は 1 つのインスタンスを使い回す
+// 毎回生成する必要が無く、ポインタの比較で等しいかを判定できる
+var (
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	// 文
+	case *ast.Program:
+		return evalProgram(node, env)
+	case *ast.ExpressionStatement:
+		return Eval(node.Expression, env)
+	case *ast.LetStatement:
+		v := Eval(node.Value, env)
+		if isError(v) {
+			return v
+		}
+		env.Set(node.Name.Value, v)
+		// let 文は値を持たない
+		return NULL
+	case *ast.ReturnStatement:
+		v := Eval(node.ReturnValue, env)
+		if isError(v) {
+			return v
+		}
+		return &object.ReturnValue{Value: v}
+
+	// 式
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.Identifier:
+		return withPos(evalIdentifier(node, env), node.Pos())
+	case *ast.GroupedExpression:
+		return Eval(node.Expression, env)
+	case *ast.PrefixExpression:
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return withPos(evalPrefixExpression(node.Operator, right), node.Token.Pos)
+	case *ast.InfixExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return withPos(evalInfixExpression(node.Operator, left, right), node.Token.Pos)
+	}
+
+	// 構文エラーで欠けた式など、値を持たないノード
+	return NULL
+}
+
+func evalProgram(prg *ast.Program, env *object.Environment) object.Object {
+	// 文が無いプログラムは値を持たない
+	var result object.Object = NULL
+	for _, s := range prg.Statements {
+		result = Eval(s, env)
+
+		// return 文かエラーに遭遇したら、残りの文は評価しない
+		switch r := result.(type) {
+		case *object.ReturnValue:
+			return r.Value
+		case *object.Error:
+			return r
+		}
+	}
+	return result
+}
+
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	v, ok := env.Get(node.Value)
+	if !ok {
+		return newError("identifier not found: %s", node.Value)
+	}
+	return v
+}
+
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return evalBangOperatorExpression(right)
+	case "-":
+		return evalMinusPrefixOperatorExpression(right)
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+// false と null 以外はすべて真として扱う
+func evalBangOperatorExpression(right object.Object) object.Object {
+	switch right {
+	case TRUE:
+		return FALSE
+	case FALSE, NULL:
+		return TRUE
+	default:
+		return FALSE
+	}
+}
+
+func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	if right.Type() != object.INTEGER_OBJ {
+		return newError("unknown operator: -%s", right.Type())
+	}
+	v := right.(*object.Integer).Value
+	return &object.Integer{Value: -v}
+}
+
+func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left, right)
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	// 真偽値はシングルトンなので、ポインタの比較で値を比較できる
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	l := left.(*object.Integer).Value
+	r := right.(*object.Integer).Value
+
+	switch operator {
+	case "+":
+		return &object.Integer{Value: l + r}
+	case "-":
+		return &object.Integer{Value: l - r}
+	case "*":
+		return &object.Integer{Value: l * r}
+	case "/":
+		// Go の整数除算はゼロ除算で panic するので、ここでエラーにする
+		if r == 0 {
+			return newError("division by zero: %d / %d", l, r)
+		}
+		return &object.Integer{Value: l / r}
+	case "<":
+		return nativeBoolToBooleanObject(l < r)
+	case ">":
+		return nativeBoolToBooleanObject(l > r)
+	case "==":
+		return nativeBoolToBooleanObject(l == r)
+	case "!=":
+		return nativeBoolToBooleanObject(l != r)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func nativeBoolToBooleanObject(b bool) *object.Boolean {
+	if b {
+		return TRUE
+	}
+	return FALSE
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+// withPos はエラーに位置が無ければ pos を設定する
+// 内側の式で起きたエラーの位置は上書きしない
+func withPos(o object.Object, pos token.Pos) object.Object {
+	if e, ok := o.(*object.Error); ok && !e.Pos.IsValid() {
+		e.Pos = pos
+	}
+	return o
+}
+
+func isError(o object.Object) bool {
+	return o != nil && o.Type() == object.ERROR_OBJ
+}
@@ -0,0 +1,246 @@
+package evaluator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/astgen"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/object"
+	"github.com/hiroygo/go-interpreter/parser"
+)
+
+func testEval(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	prg := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parse %q: %v", input, errs)
+	}
+	return Eval(prg, object.NewEnvironment())
+}
+
+func testIntegerObject(t *testing.T, o object.Object, expected int64) {
+	t.Helper()
+
+	v, ok := o.(*object.Integer)
+	if !ok {
+		t.Fatalf("%T.(*object.Integer) error, %+v", o, o)
+	}
+	if v.Value != expected {
+		t.Fatalf("want Integer.Value = %d, got %d", expected, v.Value)
+	}
+}
+
+func testBooleanObject(t *testing.T, o object.Object, expected bool) {
+	t.Helper()
+
+	v, ok := o.(*object.Boolean)
+	if !ok {
+		t.Fatalf("%T.(*object.Boolean) error, %+v", o, o)
+	}
+	if v.Value != expected {
+		t.Fatalf("want Boolean.Value = %t, got %t", expected, v.Value)
+	}
+}
+
+func TestEvalIntegerExpression(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"10", 10},
+		{"-5", -5},
+		{"--10", 10},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * 2 * 2 * 2 * 2", 32},
+		{"-50 + 100 + -50", 0},
+		{"5 * 2 + 10", 20},
+		{"5 + 2 * 10", 25},
+		{"50 / 2 * 2 + 10", 60},
+		{"2 * (5 + 10)", 30},
+		{"3 * (3 * 3) + 10", 37},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.input, func(t *testing.T) {
+			t.Parallel()
+			testIntegerObject(t, testEval(t, c.input), c.expected)
+		})
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"true == true", true},
+		{"true != false", true},
+		{"(1 < 2) == true", true},
+		{"(1 > 2) == true", false},
+		{"!true", false},
+		{"!5", false},
+		{"!!true", true},
+		{"!!5", true},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.input, func(t *testing.T) {
+			t.Parallel()
+			testBooleanObject(t, testEval(t, c.input), c.expected)
+		})
+	}
+}
+
+func TestBooleanSingleton(t *testing.T) {
+	if testEval(t, "1 < 2") != TRUE || testEval(t, "1 > 2") != FALSE {
+		t.Fatalf("want comparison results to be TRUE and FALSE singletons")
+	}
+}
+
+func TestReturnStatements(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.input, func(t *testing.T) {
+			t.Parallel()
+			testIntegerObject(t, testEval(t, c.input), c.expected)
+		})
+	}
+}
+
+func TestLetStatements(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; b;", 5},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.input, func(t *testing.T) {
+			t.Parallel()
+			testIntegerObject(t, testEval(t, c.input), c.expected)
+		})
+	}
+}
+
+// 値を持たない文や式は Go の nil ではなく NULL になる
+func TestNullResult(t *testing.T) {
+	for _, input := range []string{"let a = 5;", "1; let a = 5;", ""} {
+		if o := testEval(t, input); o != NULL {
+			t.Errorf("Eval(%q): want NULL, got %#v", input, o)
+		}
+	}
+
+	// 構文エラーで子ノードが欠けた AST
+	nodes := []ast.Node{
+		&ast.ExpressionStatement{},
+		&ast.PrefixExpression{Operator: "!"},
+		&ast.GroupedExpression{},
+	}
+	for _, n := range nodes {
+		o := Eval(n, object.NewEnvironment())
+		if o == nil {
+			t.Fatalf("Eval(%T): want an object, got nil", n)
+		}
+		o.Inspect()
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
+		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false;", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"5; true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"return true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"foobar", "identifier not found: foobar"},
+		{"let a = 1 / 0; a", "division by zero: 1 / 0"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.input, func(t *testing.T) {
+			t.Parallel()
+
+			o := testEval(t, c.input)
+			e, ok := o.(*object.Error)
+			if !ok {
+				t.Fatalf("%T.(*object.Error) error, %+v", o, o)
+			}
+			if e.Message != c.expected {
+				t.Fatalf("want Error.Message = %q, got %q", c.expected, e.Message)
+			}
+		})
+	}
+}
+
+func TestErrorPos(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int
+	}{
+		{"1 + true", 2},
+		{"let a = 1;\n-true", 11},
+		{"1 + (2 * foo)", 9},
+	}
+
+	for _, c := range cases {
+		o := testEval(t, c.input)
+		e, ok := o.(*object.Error)
+		if !ok {
+			t.Fatalf("%T.(*object.Error) error, %+v", o, o)
+		}
+		if e.Pos.Offset() != c.expected {
+			t.Errorf("%q: want Error.Pos.Offset() = %d, got %d", c.input, c.expected, e.Pos.Offset())
+		}
+	}
+}
+
+// どんなプログラムでも panic せずに値かエラーを返す
+func TestEvalRandomPrograms(t *testing.T) {
+	astgen.Check(t, 1, 500, astgen.Config{}, func(prg *ast.Program) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		env := object.NewEnvironment()
+		for _, name := range astgen.DefaultNames {
+			env.Set(name, &object.Integer{Value: 1})
+		}
+		Eval(prg, env)
+		return nil
+	})
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hiroygo/go-interpreter/parser"
+)
+
+// 'explain <code>'
+func runExplain(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "usage: go-interpreter explain <code>")
+		return exitUsage
+	}
+	e, ok := parser.Explain(args[0])
+	if !ok {
+		var codes []string
+		for _, c := range parser.Codes() {
+			codes = append(codes, string(c))
+		}
+		fmt.Fprintf(stderr, "unknown error code %q, available: %s\n", args[0], strings.Join(codes, ", "))
+		return exitUsage
+	}
+
+	fmt.Fprintf(stdout, "%s: %s\n\n%s\n\n", e.Code, e.Title, e.Text)
+	fmt.Fprintf(stdout, "Erroneous example:\n\n%s\n\n", indent(e.Erroneous))
+	fmt.Fprintf(stdout, "Corrected example:\n\n%s\n", indent(e.Corrected))
+	return exitOK
+}
+
+// indent はコードの例を字下げする
+func indent(src string) string {
+	return "    " + strings.ReplaceAll(src, "\n", "\n    ")
+}
@@ -1,3 +1,3 @@
 module github.com/hiroygo/go-interpreter
 
-go 1.16
+go 1.23
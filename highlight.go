@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/hiroygo/go-interpreter/highlight"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// 'highlight [-format ansi|html] [-css] <file>'
+func runHighlight(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("highlight", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "ansi", "output format, ansi or html")
+	css := fs.Bool("css", false, "with -format html, prepend a <style> element")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: go-interpreter highlight [-format ansi|html] [-css] <file>")
+		return exitUsage
+	}
+	src, _, err := readSource(fs.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	switch *format {
+	case "ansi":
+		err = highlight.ANSI(stdout, src, token.Standard)
+	case "html":
+		if *css {
+			fmt.Fprintf(stdout, "<style>\n%s</style>\n", highlight.Stylesheet)
+		}
+		err = highlight.HTML(stdout, src, token.Standard)
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,152 @@
+// highlight は字句解析したトークンの種類ごとにソースを色付けする
+// 空白などトークンの間の文字は、そのまま出力する
+package highlight
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Class はトークンの分類を表す
+type Class int
+
+const (
+	// 空白など、トークンではない部分
+	Plain Class = iota
+	Keyword
+	Identifier
+	Number
+	Operator
+	Delimiter
+	Illegal
+)
+
+// String は HTML の CSS クラス名として使う
+func (c Class) String() string {
+	switch c {
+	case Plain:
+		return "plain"
+	case Keyword:
+		return "keyword"
+	case Identifier:
+		return "identifier"
+	case Number:
+		return "number"
+	case Operator:
+		return "operator"
+	case Delimiter:
+		return "delimiter"
+	case Illegal:
+		return "illegal"
+	}
+	return fmt.Sprintf("Class(%d)", int(c))
+}
+
+// classify は t の分類を返す
+// キーワードかどうかは d の綴りで判定する
+func classify(t token.Token, d *token.Dialect) Class {
+	switch t.Type {
+	case token.ILLEGAL:
+		return Illegal
+	case token.IDENT:
+		return Identifier
+	case token.INT:
+		return Number
+	case token.ASSIGN, token.PLUS, token.MINUS, token.BANG, token.ASTERISK,
+		token.SLASH, token.LT, token.GT, token.EQ, token.NOT_EQ:
+		return Operator
+	case token.COMMA, token.SEMICOLON, token.LPAREN, token.RPAREN, token.LBRACE, token.RBRACE:
+		return Delimiter
+	}
+	if d.LookupIdent(t.Literal) != token.IDENT {
+		return Keyword
+	}
+	return Plain
+}
+
+// Span はソースの一部とその分類を表す
+type Span struct {
+	Class Class
+	Text  string
+}
+
+// Spans は d のキーワードで書かれた src を分類ごとに区切って返す
+// すべての Span の Text をつなげると src に戻る
+func Spans(src string, d *token.Dialect) []Span {
+	var spans []Span
+	add := func(c Class, text string) {
+		if text == "" {
+			return
+		}
+		spans = append(spans, Span{Class: c, Text: text})
+	}
+
+	offset := 0
+	for t := range lexer.Tokens(src, lexer.WithDialect(d)) {
+		start := t.Pos.Offset()
+		add(Plain, src[offset:start])
+		add(classify(t, d), t.Literal)
+		offset = start + len(t.Literal)
+	}
+	add(Plain, src[offset:])
+	return spans
+}
+
+// ansiColors は分類ごとの 256 色の色番号
+var ansiColors = map[Class]int{
+	Keyword:    170,
+	Identifier: 75,
+	Number:     179,
+	Operator:   203,
+	Delimiter:  245,
+	Illegal:    196,
+}
+
+// ANSI は src を 256 色のエスケープシーケンスで色付けして w に出力する
+func ANSI(w io.Writer, src string, d *token.Dialect) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range Spans(src, d) {
+		c, ok := ansiColors[s.Class]
+		if !ok {
+			bw.WriteString(s.Text)
+			continue
+		}
+		if s.Class == Illegal {
+			// 不正なトークンは下線も付けて目立たせる
+			fmt.Fprintf(bw, "\x1b[4;38;5;%dm%s\x1b[0m", c, s.Text)
+			continue
+		}
+		fmt.Fprintf(bw, "\x1b[38;5;%dm%s\x1b[0m", c, s.Text)
+	}
+	return bw.Flush()
+}
+
+// HTML は src を '<pre class="highlight">' で囲み、分類ごとに CSS クラスを付けて w に出力する
+// 色は Stylesheet のような CSS で指定する
+func HTML(w io.Writer, src string, d *token.Dialect) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<pre class="highlight"><code>`)
+	for _, s := range Spans(src, d) {
+		if s.Class == Plain {
+			bw.WriteString(html.EscapeString(s.Text))
+			continue
+		}
+		fmt.Fprintf(bw, `<span class="%s">%s</span>`, s.Class, html.EscapeString(s.Text))
+	}
+	bw.WriteString("</code></pre>\n")
+	return bw.Flush()
+}
+
+// Stylesheet は HTML の出力に合わせた CSS
+const Stylesheet = `.highlight .keyword { color: #d75fd7; font-weight: bold; }
+.highlight .identifier { color: #5fafff; }
+.highlight .number { color: #d7af5f; }
+.highlight .operator { color: #ff5f5f; }
+.highlight .delimiter { color: #8a8a8a; }
+.highlight .illegal { color: #ff0000; text-decoration: underline wavy; }
+`
@@ -0,0 +1,76 @@
+package highlight
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+func TestSpans(t *testing.T) {
+	input := "#!/usr/bin/env go-interpreter run\nlet x\t= fn(1) != é;\r\n  "
+
+	spans := Spans(input, token.Standard)
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(s.Text)
+	}
+	if b.String() != input {
+		t.Fatalf("want Spans() to preserve the source %q, got %q", input, b.String())
+	}
+
+	var actual []string
+	for _, s := range spans {
+		if s.Class != Plain {
+			actual = append(actual, s.Class.String()+":"+s.Text)
+		}
+	}
+	expected := []string{
+		"keyword:let", "identifier:x", "operator:=", "keyword:fn", "delimiter:(",
+		"number:1", "delimiter:)", "operator:!=", "illegal:é", "delimiter:;",
+	}
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Fatalf("want spans %q, got %q", expected, actual)
+	}
+}
+
+func TestSpansDialect(t *testing.T) {
+	var actual []string
+	for _, s := range Spans("変数 x = 真; let", token.Japanese) {
+		if s.Class != Plain {
+			actual = append(actual, s.Class.String()+":"+s.Text)
+		}
+	}
+	// 日本語の Dialect では 'let' は識別子
+	expected := []string{
+		"keyword:変数", "identifier:x", "operator:=", "keyword:真", "delimiter:;", "identifier:let",
+	}
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Fatalf("want spans %q, got %q", expected, actual)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var b bytes.Buffer
+	if err := HTML(&b, "a < 1;\n", token.Standard); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<pre class="highlight"><code><span class="identifier">a</span> ` +
+		`<span class="operator">&lt;</span> <span class="number">1</span>` +
+		`<span class="delimiter">;</span>` + "\n</code></pre>\n"
+	if b.String() != expected {
+		t.Fatalf("want HTML() = %q, got %q", expected, b.String())
+	}
+}
+
+func TestANSI(t *testing.T) {
+	var b bytes.Buffer
+	if err := ANSI(&b, "true  x", token.Standard); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\x1b[38;5;170mtrue\x1b[0m  \x1b[38;5;75mx\x1b[0m"
+	if b.String() != expected {
+		t.Fatalf("want ANSI() = %q, got %q", expected, b.String())
+	}
+}
@@ -0,0 +1,69 @@
+// golden は testdata のソースを処理した結果を、期待する結果のファイルと比べる
+//
+// 'go test ./lexer ./parser -update' で期待する結果のファイルを書き換える
+package golden
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Run は dir の *.mk を 1 つずつ f に渡し、その結果を '<name>.<ext>' のファイルと比べる
+// 結果が空のときはファイルが無いことを期待する
+// e.g. 'let.mk' の字句解析の結果は 'let.tokens'
+func Run(t *testing.T, dir, ext string, f func(t *testing.T, src string) string) {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.mk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no *.mk files in %s", dir)
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".mk")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := f(t, string(src))
+			golden := strings.TrimSuffix(path, ".mk") + "." + ext
+
+			if *update {
+				write(t, golden, actual)
+				return
+			}
+
+			b, err := os.ReadFile(golden)
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			if expected := string(b); actual != expected {
+				t.Fatalf("%s does not match, run 'go test -update' if the change is intended\nwant:\n%s\ngot:\n%s",
+					golden, expected, actual)
+			}
+		})
+	}
+}
+
+func write(t *testing.T, golden, content string) {
+	t.Helper()
+
+	if content == "" {
+		if err := os.Remove(golden); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return
+	}
+	if err := os.WriteFile(golden, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
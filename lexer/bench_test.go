@@ -0,0 +1,105 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// generate は n 個の関数定義からなる大きなソースを返す
+// 名前は 16 種類に限ることで、生成されたファイルのように同じ名前が何度も出てくるようにする
+func generate(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "let f%d = fn(a, b) {\n", i%16)
+		b.WriteString("\t// コメント\n")
+		b.WriteString("\tif (a == b) { return !true; } else { return a * b + 10 / 2 - 1; }\n")
+		b.WriteString("\tlet x = a != b;\n")
+		b.WriteString("\treturn x < b > a;\n")
+		b.WriteString("};\n")
+	}
+	return b.String()
+}
+
+func countTokens(src string) int {
+	n := 0
+	l := New(src)
+	for l.NextToken().Type != token.EOF {
+		n++
+	}
+	return n
+}
+
+func TestAllocsPerToken(t *testing.T) {
+	src := generate(1000)
+	tokens := countTokens(src)
+	allocs := testing.AllocsPerRun(10, func() {
+		l := New(src)
+		for l.NextToken().Type != token.EOF {
+		}
+	})
+	// New の分だけ確保する
+	if perToken := allocs / float64(tokens); perToken > 0.001 {
+		t.Fatalf("want almost no allocations per token, got %v (%v allocs for %d tokens)", perToken, allocs, tokens)
+	}
+}
+
+func BenchmarkNextToken(b *testing.B) {
+	src := generate(1000)
+	tokens := countTokens(src)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := New(src)
+		for l.NextToken().Type != token.EOF {
+		}
+	}
+	b.ReportMetric(float64(testing.AllocsPerRun(1, func() {
+		l := New(src)
+		for l.NextToken().Type != token.EOF {
+		}
+	}))/float64(tokens), "allocs/token")
+}
+
+// 共有した Interner で識別子をまとめる
+func BenchmarkNextTokenInterner(b *testing.B) {
+	src := generate(1000)
+	in := token.NewInterner()
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := New(src, WithInterner(in))
+		for l.NextToken().Type != token.EOF {
+		}
+	}
+}
+
+func BenchmarkNextTokenJapanese(b *testing.B) {
+	src, err := Translate(generate(1000), token.Standard, token.Japanese)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := New(src, WithDialect(token.Japanese))
+		for l.NextToken().Type != token.EOF {
+		}
+	}
+}
+
+func BenchmarkTokenize(b *testing.B) {
+	src := generate(1000)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Tokenize(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/internal/golden"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// testdata の字句解析の結果を '<name>.tokens' と比べる
+func TestGolden(t *testing.T) {
+	golden.Run(t, "../testdata", "tokens", func(t *testing.T, src string) string {
+		file := token.NewFile("", src)
+		var b strings.Builder
+		for tok := range Tokens(src) {
+			fmt.Fprintf(&b, "%s\t%s\t%q\n", file.Position(tok.Pos), tok.Type, tok.Literal)
+		}
+		return b.String()
+	})
+}
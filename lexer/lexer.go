@@ -1,20 +1,106 @@
 package lexer
 
-import "github.com/hiroygo/go-interpreter/token"
+import (
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/hiroygo/go-interpreter/message"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Lexer が返すトークンのリテラルはすべて input の部分文字列にする
+// 部分文字列は input を指すだけなので、トークンごとに文字列を作らない
 type Lexer struct {
 	input        string
 	position     int  // 入力における現在の位置(現在の文字を指し示す)
 	readPosition int  // これから読み込む位置(現在の文字の次)
 	ch           byte // 現在の文字
+	// キーワードの綴り
+	dialect *token.Dialect
+	// 識別子の綴りをまとめる
+	// nil のときは識別子も input の部分文字列にする
+	names *token.Interner
+	// 字句解析を始める位置
+	offset int
+	// Tokenize が返すエラーのメッセージの言語
+	lang message.Language
+}
+
+// Option は New で Lexer の設定を変える
+type Option func(*Lexer)
+
+// WithDialect はキーワードの綴りを d にする
+// 指定しないときは token.Standard
+func WithDialect(d *token.Dialect) Option {
+	return func(l *Lexer) {
+		l.dialect = d
+	}
+}
+
+// WithInterner は識別子の綴りを in にまとめる
+// 識別子のリテラルは input ではなく in が覚えた複製になるので、AST を残しても input を解放できる
+// 複数の Lexer で同じ名前を共有したいときに使う
+// 指定しないときは識別子も input の部分文字列にする
+func WithInterner(in *token.Interner) Option {
+	return func(l *Lexer) {
+		l.names = in
+	}
+}
+
+// WithOffset は input の先頭からのバイトオフセット offset から字句解析を始める
+// トークンの位置は input の先頭から数える
+// ソースの一部だけを字句解析し直すときに使う
+func WithOffset(offset int) Option {
+	return func(l *Lexer) {
+		l.offset = offset
+	}
 }
 
-func New(s string) *Lexer {
-	l := &Lexer{input: s}
+// WithLanguage は Tokenize が返すエラーのメッセージの言語を lang にする
+// 指定しないときは message.English
+func WithLanguage(lang message.Language) Option {
+	return func(l *Lexer) {
+		l.lang = lang
+	}
+}
+
+func New(s string, opts ...Option) *Lexer {
+	l := &Lexer{input: s, dialect: token.Standard, lang: message.English}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.start()
+	return l
+}
+
+// Reset は設定をそのままにして、l で s を新しく字句解析できるようにする
+// WithOffset の位置は使わず、先頭から字句解析する
+func (l *Lexer) Reset(s string) {
+	l.input = s
+	l.offset = 0
+	l.start()
+}
+
+func (l *Lexer) start() {
 	// NextToken の実行前に呼び出す必要がある
 	// position などを設定するため
+	l.readPosition = l.offset
 	l.readChar()
-	return l
+	// '#!' の行はファイルの先頭にしか無い
+	if l.offset == 0 {
+		l.skipShebang()
+	}
+}
+
+// スクリプトを実行可能ファイルにできるように
+// 先頭の '#!' で始まる行は読み飛ばす
+func (l *Lexer) skipShebang() {
+	if l.ch != '#' || l.peekChar() != '!' {
+		return
+	}
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
 }
 
 func (l *Lexer) readChar() {
@@ -27,15 +113,27 @@ func (l *Lexer) readChar() {
 	l.readPosition++
 }
 
+// '//' から行末まではコメントとして空白と同じように読み飛ばす
 func (l *Lexer) eatWhiteSpace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-		l.readChar()
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			for l.ch != '\n' && l.ch != 0 {
+				l.readChar()
+			}
+		default:
+			return
+		}
 	}
 }
 
+// 先頭以外には数字も使える
+// e.g. 'arg1'
 func (l *Lexer) readIdentifier() string {
 	head := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[head:l.position]
@@ -49,6 +147,39 @@ func (l *Lexer) readNumber() string {
 	return l.input[head:l.position]
 }
 
+// readRune は現在位置の UTF-8 の 1 文字を読み込む
+// 不正なバイト列のときは 1 バイトだけ読み込む
+func (l *Lexer) readRune() string {
+	head := l.position
+	_, size := utf8.DecodeRuneInString(l.input[head:])
+	for i := 0; i < size; i++ {
+		l.readChar()
+	}
+	return l.input[head:l.position]
+}
+
+// readKeyword は現在位置から続く ASCII 以外の文字が Dialect のキーワードのときに読み込む
+// キーワードではないときは何も読み込まない
+func (l *Lexer) readKeyword() (string, bool) {
+	head := l.position
+	end := head
+	for end < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[end:])
+		if r < utf8.RuneSelf || !unicode.IsLetter(r) {
+			break
+		}
+		end += size
+	}
+	word := l.input[head:end]
+	if l.dialect.LookupIdent(word) == token.IDENT {
+		return "", false
+	}
+	for l.position < end {
+		l.readChar()
+	}
+	return word, true
+}
+
 func (l *Lexer) peekChar() byte {
 	if l.readPosition >= len(l.input) {
 		return 0
@@ -59,73 +190,90 @@ func (l *Lexer) peekChar() byte {
 func (l *Lexer) NextToken() token.Token {
 	l.eatWhiteSpace()
 	c := l.ch
+	// トークンの先頭の位置
+	pos := token.PosFromOffset(l.position)
 
 	t := token.Token{}
 	switch c {
 	case '=':
 		p := l.peekChar()
 		if p == '=' {
-			t = token.Token{Type: token.EQ, Literal: string(c) + string(p)}
+			t = l.newToken(token.EQ, 2)
 			l.readChar()
 		} else {
-			t = newToken(token.ASSIGN, c)
+			t = l.newToken(token.ASSIGN, 1)
 		}
 	case '+':
-		t = newToken(token.PLUS, c)
+		t = l.newToken(token.PLUS, 1)
 	case '-':
-		t = newToken(token.MINUS, c)
+		t = l.newToken(token.MINUS, 1)
 	case '!':
 		p := l.peekChar()
 		if p == '=' {
-			t = token.Token{Type: token.NOT_EQ, Literal: string(c) + string(p)}
+			t = l.newToken(token.NOT_EQ, 2)
 			l.readChar()
 		} else {
-			t = newToken(token.BANG, c)
+			t = l.newToken(token.BANG, 1)
 		}
 	case '/':
-		t = newToken(token.SLASH, c)
+		t = l.newToken(token.SLASH, 1)
 	case '*':
-		t = newToken(token.ASTERISK, c)
+		t = l.newToken(token.ASTERISK, 1)
 	case '<':
-		t = newToken(token.LT, c)
+		t = l.newToken(token.LT, 1)
 	case '>':
-		t = newToken(token.GT, c)
+		t = l.newToken(token.GT, 1)
 	case ';':
-		t = newToken(token.SEMICOLON, c)
+		t = l.newToken(token.SEMICOLON, 1)
 	case ',':
-		t = newToken(token.COMMA, c)
+		t = l.newToken(token.COMMA, 1)
 	case '{':
-		t = newToken(token.LBRACE, c)
+		t = l.newToken(token.LBRACE, 1)
 	case '}':
-		t = newToken(token.RBRACE, c)
+		t = l.newToken(token.RBRACE, 1)
 	case '(':
-		t = newToken(token.LPAREN, c)
+		t = l.newToken(token.LPAREN, 1)
 	case ')':
-		t = newToken(token.RPAREN, c)
+		t = l.newToken(token.RPAREN, 1)
 	case 0:
-		t = token.Token{Type: token.EOF, Literal: ""}
+		// EOF の後は何度呼ばれても位置を進めない
+		return token.Token{Type: token.EOF, Literal: "", Pos: pos}
 	default:
 		// 言語のキーワードか変数名がここに来る
 		if isLetter(c) {
 			ident := l.readIdentifier()
-			tt := token.LookupIdent(ident)
+			tt := l.dialect.LookupIdent(ident)
+			if tt == token.IDENT && l.names != nil {
+				ident = l.names.Intern(ident)
+			}
 			// ここで return するのは readIdentifier() で
 			// 次の読み取るべき位置に移動済だから
-			return token.Token{Type: tt, Literal: ident}
+			return token.Token{Type: tt, Literal: ident, Pos: pos}
 		}
 		if isDigit(c) {
 			strNum := l.readNumber()
-			return token.Token{Type: token.INT, Literal: strNum}
+			return token.Token{Type: token.INT, Literal: strNum, Pos: pos}
+		}
+		if c >= utf8.RuneSelf {
+			// '関数' のように ASCII 以外の文字のキーワードがある
+			if kw, ok := l.readKeyword(); ok {
+				return token.Token{Type: l.dialect.LookupIdent(kw), Literal: kw, Pos: pos}
+			}
+			// ASCII 以外の文字は 1 文字を 1 つの不正なトークンにする
+			// string(c) だと 1 バイトが別の文字に変換されてしまうため
+			return token.Token{Type: token.ILLEGAL, Literal: l.readRune(), Pos: pos}
 		}
-		t = newToken(token.ILLEGAL, c)
+		t = l.newToken(token.ILLEGAL, 1)
 	}
 
 	l.readChar()
+	t.Pos = pos
 	return t
 }
 
-func newToken(t token.TokenType, c byte) token.Token {
-	return token.Token{Type: t, Literal: string(c)}
+// newToken は現在位置から n バイトの記号のトークンを返す
+func (l *Lexer) newToken(t token.TokenType, n int) token.Token {
+	return token.Token{Type: t, Literal: l.input[l.position : l.position+n]}
 }
 
 func isLetter(c byte) bool {
@@ -1,8 +1,12 @@
 package lexer
 
 import (
+	"slices"
+	"strings"
 	"testing"
+	"unsafe"
 
+	"github.com/hiroygo/go-interpreter/message"
 	"github.com/hiroygo/go-interpreter/token"
 )
 
@@ -28,87 +32,393 @@ if (5 < 10) {
 10 != 9;
 `
 	expected := []token.Token{
-		{token.LET, "let"},
-		{token.IDENT, "five"},
-		{token.ASSIGN, "="},
-		{token.INT, "5"},
-		{token.SEMICOLON, ";"},
-		{token.LET, "let"},
-		{token.IDENT, "ten"},
-		{token.ASSIGN, "="},
-		{token.INT, "10"},
-		{token.SEMICOLON, ";"},
-		{token.LET, "let"},
-		{token.IDENT, "add"},
-		{token.ASSIGN, "="},
-		{token.FUNCTION, "fn"},
-		{token.LPAREN, "("},
-		{token.IDENT, "x"},
-		{token.COMMA, ","},
-		{token.IDENT, "y"},
-		{token.RPAREN, ")"},
-		{token.LBRACE, "{"},
-		{token.IDENT, "x"},
-		{token.PLUS, "+"},
-		{token.IDENT, "y"},
-		{token.SEMICOLON, ";"},
-		{token.RBRACE, "}"},
-		{token.SEMICOLON, ";"},
-		{token.LET, "let"},
-		{token.IDENT, "result"},
-		{token.ASSIGN, "="},
-		{token.IDENT, "add"},
-		{token.LPAREN, "("},
-		{token.IDENT, "five"},
-		{token.COMMA, ","},
-		{token.IDENT, "ten"},
-		{token.RPAREN, ")"},
-		{token.SEMICOLON, ";"},
-		{token.BANG, "!"},
-		{token.MINUS, "-"},
-		{token.SLASH, "/"},
-		{token.ASTERISK, "*"},
-		{token.INT, "5"},
-		{token.SEMICOLON, ";"},
-		{token.INT, "5"},
-		{token.LT, "<"},
-		{token.INT, "10"},
-		{token.GT, ">"},
-		{token.INT, "5"},
-		{token.SEMICOLON, ";"},
-		{token.IF, "if"},
-		{token.LPAREN, "("},
-		{token.INT, "5"},
-		{token.LT, "<"},
-		{token.INT, "10"},
-		{token.RPAREN, ")"},
-		{token.LBRACE, "{"},
-		{token.RETURN, "return"},
-		{token.TRUE, "true"},
-		{token.SEMICOLON, ";"},
-		{token.RBRACE, "}"},
-		{token.ELSE, "else"},
-		{token.LBRACE, "{"},
-		{token.RETURN, "return"},
-		{token.FALSE, "false"},
-		{token.SEMICOLON, ";"},
-		{token.RBRACE, "}"},
-		{token.INT, "10"},
-		{token.EQ, "=="},
-		{token.INT, "10"},
-		{token.SEMICOLON, ";"},
-		{token.INT, "10"},
-		{token.NOT_EQ, "!="},
-		{token.INT, "9"},
-		{token.SEMICOLON, ";"},
-		{token.EOF, ""},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENT, Literal: "five"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.INT, Literal: "5"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENT, Literal: "ten"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.INT, Literal: "10"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENT, Literal: "add"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.FUNCTION, Literal: "fn"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.IDENT, Literal: "y"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.PLUS, Literal: "+"},
+		{Type: token.IDENT, Literal: "y"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENT, Literal: "result"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.IDENT, Literal: "add"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.IDENT, Literal: "five"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.IDENT, Literal: "ten"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.BANG, Literal: "!"},
+		{Type: token.MINUS, Literal: "-"},
+		{Type: token.SLASH, Literal: "/"},
+		{Type: token.ASTERISK, Literal: "*"},
+		{Type: token.INT, Literal: "5"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.INT, Literal: "5"},
+		{Type: token.LT, Literal: "<"},
+		{Type: token.INT, Literal: "10"},
+		{Type: token.GT, Literal: ">"},
+		{Type: token.INT, Literal: "5"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IF, Literal: "if"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.INT, Literal: "5"},
+		{Type: token.LT, Literal: "<"},
+		{Type: token.INT, Literal: "10"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.RETURN, Literal: "return"},
+		{Type: token.TRUE, Literal: "true"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.ELSE, Literal: "else"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.RETURN, Literal: "return"},
+		{Type: token.FALSE, Literal: "false"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.INT, Literal: "10"},
+		{Type: token.EQ, Literal: "=="},
+		{Type: token.INT, Literal: "10"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.INT, Literal: "10"},
+		{Type: token.NOT_EQ, Literal: "!="},
+		{Type: token.INT, Literal: "9"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.EOF, Literal: ""},
 	}
 
 	lex := New(input)
 	for _, tok := range expected {
 		actual := lex.NextToken()
-		if tok != actual {
+		if tok.Type != actual.Type || tok.Literal != actual.Literal {
 			t.Fatalf("want NextToken() = %+v, got %+v", tok, actual)
 		}
 	}
 }
+
+func TestIdentifierDigits(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []token.Token
+	}{
+		{"x1", []token.Token{{Type: token.IDENT, Literal: "x1"}}},
+		{"a_2b3", []token.Token{{Type: token.IDENT, Literal: "a_2b3"}}},
+		// 数字から始まる識別子は無い
+		{"1x", []token.Token{{Type: token.INT, Literal: "1"}, {Type: token.IDENT, Literal: "x"}}},
+		{"let1", []token.Token{{Type: token.IDENT, Literal: "let1"}}},
+	}
+
+	for _, c := range cases {
+		lex := New(c.input)
+		for _, tok := range append(c.expected, token.Token{Type: token.EOF}) {
+			actual := lex.NextToken()
+			if tok.Type != actual.Type || tok.Literal != actual.Literal {
+				t.Fatalf("%q: want NextToken() = %+v, got %+v", c.input, tok, actual)
+			}
+		}
+	}
+}
+
+func TestComment(t *testing.T) {
+	input := "x; // comment\n// comment\ny // ; z\n/"
+	expected := []token.Token{
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IDENT, Literal: "y"},
+		// '/' が 1 つだけのときはコメントではない
+		{Type: token.SLASH, Literal: "/"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lex := New(input)
+	for _, tok := range expected {
+		actual := lex.NextToken()
+		if tok.Type != actual.Type || tok.Literal != actual.Literal {
+			t.Fatalf("want NextToken() = %+v, got %+v", tok, actual)
+		}
+	}
+}
+
+func TestNextTokenPos(t *testing.T) {
+	input := "let x = 10;\n  x != 5"
+	expected := []struct {
+		literal string
+		offset  int
+	}{
+		{"let", 0},
+		{"x", 4},
+		{"=", 6},
+		{"10", 8},
+		{";", 10},
+		{"x", 14},
+		{"!=", 16},
+		{"5", 19},
+		{"", 20},
+		// EOF の後は位置が変わらない
+		{"", 20},
+	}
+
+	lex := New(input)
+	for _, e := range expected {
+		actual := lex.NextToken()
+		if actual.Literal != e.literal {
+			t.Fatalf("want Token.Literal = %q, got %q", e.literal, actual.Literal)
+		}
+		if actual.Pos.Offset() != e.offset {
+			t.Fatalf("want %q Token.Pos.Offset() = %d, got %d", e.literal, e.offset, actual.Pos.Offset())
+		}
+		if actual.End().Offset() != e.offset+len(e.literal) {
+			t.Fatalf("want %q Token.End().Offset() = %d, got %d", e.literal, e.offset+len(e.literal), actual.End().Offset())
+		}
+	}
+}
+
+func TestShebang(t *testing.T) {
+	input := "#!/usr/bin/env go-interpreter run\nlet x = 1;"
+
+	lex := New(input)
+	tok := lex.NextToken()
+	if tok.Type != token.LET {
+		t.Fatalf("want first Token.Type = %q, got %q", token.LET, tok.Type)
+	}
+	// 読み飛ばした行の分も位置に含まれる
+	if tok.Pos.Offset() != 34 {
+		t.Fatalf("want Token.Pos.Offset() = 34, got %d", tok.Pos.Offset())
+	}
+
+	// 先頭以外の '#' は読み飛ばさない
+	tok = New(" #!").NextToken()
+	if tok.Type != token.ILLEGAL {
+		t.Fatalf("want Token.Type = %q, got %q", token.ILLEGAL, tok.Type)
+	}
+}
+
+func TestNonASCII(t *testing.T) {
+	input := "x é\xff😀"
+	expected := []token.Token{
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.ILLEGAL, Literal: "é"},
+		{Type: token.ILLEGAL, Literal: "\xff"},
+		{Type: token.ILLEGAL, Literal: "😀"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lex := New(input)
+	for _, tok := range expected {
+		actual := lex.NextToken()
+		if tok.Type != actual.Type || tok.Literal != actual.Literal {
+			t.Fatalf("want NextToken() = %+v, got %+v", tok, actual)
+		}
+		// トークンの範囲はソースと一致する
+		if input[actual.Pos.Offset():actual.End().Offset()] != actual.Literal {
+			t.Fatalf("want Token span = %q, got %q", actual.Literal, input[actual.Pos.Offset():actual.End().Offset()])
+		}
+	}
+}
+
+func TestDialect(t *testing.T) {
+	input := "変数 x = もし(真) { 返す 偽 } でなければ { 関数 }; fn 変"
+	expected := []token.Token{
+		{Type: token.LET, Literal: "変数"},
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.IF, Literal: "もし"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.TRUE, Literal: "真"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.RETURN, Literal: "返す"},
+		{Type: token.FALSE, Literal: "偽"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.ELSE, Literal: "でなければ"},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.FUNCTION, Literal: "関数"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		// 標準のキーワードは識別子になる
+		{Type: token.IDENT, Literal: "fn"},
+		// キーワードではない ASCII 以外の文字は、これまでどおり不正なトークンになる
+		{Type: token.ILLEGAL, Literal: "変"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lex := New(input, WithDialect(token.Japanese))
+	for _, tok := range expected {
+		actual := lex.NextToken()
+		if tok.Type != actual.Type || tok.Literal != actual.Literal {
+			t.Fatalf("want NextToken() = %+v, got %+v", tok, actual)
+		}
+		if input[actual.Pos.Offset():actual.End().Offset()] != actual.Literal {
+			t.Fatalf("want Token span = %q, got %q", actual.Literal, input[actual.Pos.Offset():actual.End().Offset()])
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	cases := []struct {
+		input    string
+		from, to *token.Dialect
+		expected string
+		err      bool
+	}{
+		{
+			"#!/usr/bin/env go-interpreter run\nlet x = !true;  // 真\n\treturn  x;",
+			token.Standard, token.Japanese,
+			"#!/usr/bin/env go-interpreter run\n変数 x = !真;  // 真\n\t返す  x;",
+			false,
+		},
+		{
+			"もし (偽) { 1 } でなければ { 2 }",
+			token.Japanese, token.Standard,
+			"if (false) { 1 } else { 2 }",
+			false,
+		},
+		// 日本語の識別子 'fn' は標準のキーワードと区別できない
+		{"変数 fn = 1;", token.Japanese, token.Standard, "", true},
+		// 標準では不正な '変' '数' が日本語の let になってしまう
+		{"let x = 変数;", token.Standard, token.Japanese, "", true},
+	}
+
+	for _, c := range cases {
+		actual, err := Translate(c.input, c.from, c.to)
+		if (err != nil) != c.err {
+			t.Fatalf("Translate(%q): want error %v, got %v", c.input, c.err, err)
+		}
+		if actual != c.expected {
+			t.Fatalf("Translate(%q): want %q, got %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	ts, err := Tokenize("let x = 1;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, tok := range ts {
+		actual = append(actual, tok.Literal)
+	}
+	if strings.Join(actual, " ") != "let x = 1 ;" {
+		t.Fatalf("want tokens [let x = 1 ;], got %q", actual)
+	}
+
+	// 不正なトークンがあっても最後まで読む
+	ts, err = Tokenize("a @ b $")
+	if len(ts) != 4 {
+		t.Fatalf("want 4 tokens, got %v", ts)
+	}
+	errs, ok := err.(ErrorList)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("want 2 errors, got %v", err)
+	}
+	if errs[0].Pos.Offset() != 2 || errs[1].Literal != "$" {
+		t.Fatalf("want errors at @ and $, got %v", errs)
+	}
+	// 構文エラーと同じコードとメッセージを使う
+	if errs[0].Code != message.ErrIllegalCharacter || errs[0].End.Offset() != 3 {
+		t.Fatalf("want E0004 for @, got %+v", errs[0])
+	}
+	expected := `illegal character "@" (and 1 more errors)`
+	if err.Error() != expected {
+		t.Fatalf("want Error() = %q, got %q", expected, err.Error())
+	}
+
+	_, err = Tokenize("é", WithLanguage(message.Japanese))
+	if expected := `"é" は使えない文字です`; err == nil || err.Error() != expected {
+		t.Fatalf("want Error() = %q, got %v", expected, err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	l := New("a + b; c")
+	var actual []string
+	for tok := range l.All() {
+		actual = append(actual, tok.Literal)
+		// 途中で止めても残りのトークンは消費されない
+		if tok.Type == token.SEMICOLON {
+			break
+		}
+	}
+	if strings.Join(actual, " ") != "a + b ;" {
+		t.Fatalf("want tokens [a + b ;], got %q", actual)
+	}
+	if tok := l.NextToken(); tok.Literal != "c" {
+		t.Fatalf("want NextToken() = c, got %+v", tok)
+	}
+}
+
+func TestLookahead(t *testing.T) {
+	la := NewLookahead(New("a + 1"))
+	if tok := la.Peek(2); tok.Literal != "1" {
+		t.Fatalf("want Peek(2) = 1, got %+v", tok)
+	}
+	if tok := la.Peek(5); tok.Type != token.EOF {
+		t.Fatalf("want Peek(5) = EOF, got %+v", tok)
+	}
+	for _, e := range []string{"a", "+", "1"} {
+		if tok := la.Next(); tok.Literal != e {
+			t.Fatalf("want Next() = %q, got %+v", e, tok)
+		}
+	}
+	// 入力の終わりの後は何度でも EOF を返す
+	for i := 0; i < 2; i++ {
+		if tok := la.Next(); tok.Type != token.EOF {
+			t.Fatalf("want Next() = EOF, got %+v", tok)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	l := New("let a = 1;", WithDialect(token.Japanese))
+	for l.NextToken().Type != token.EOF {
+	}
+	l.Reset("変数 b = 2;")
+	got, want := []token.TokenType{}, []token.TokenType{token.LET, token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON}
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		got = append(got, tok.Type)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("want %v after Reset, got %v", want, got)
+	}
+	if tok := l.NextToken(); tok.Pos != token.PosFromOffset(len("変数 b = 2;")) {
+		t.Fatalf("want EOF at the end of the new input, got %d", tok.Pos)
+	}
+}
+
+func TestInterner(t *testing.T) {
+	src := "foo + foo"
+	// 指定しないときはソースの部分文字列
+	tok := New(src).NextToken()
+	if unsafe.StringData(tok.Literal) != unsafe.StringData(src) {
+		t.Fatal("want the literal to be a substring of the source")
+	}
+
+	in := token.NewInterner()
+	l := New(src, WithInterner(in))
+	a, _, b := l.NextToken(), l.NextToken(), l.NextToken()
+	if unsafe.StringData(a.Literal) != unsafe.StringData(b.Literal) || unsafe.StringData(a.Literal) == unsafe.StringData(src) {
+		t.Fatal("want both identifiers to share the interned copy")
+	}
+}
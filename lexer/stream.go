@@ -0,0 +1,121 @@
+package lexer
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/hiroygo/go-interpreter/message"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Error は字句解析できなかった文字を表す
+// 構文エラーと同じく、位置は token.File で 'line:col' にして表示する
+type Error struct {
+	// 字句解析できなかった文字の範囲
+	Pos token.Pos
+	End token.Pos
+	// 常に message.ErrIllegalCharacter
+	Code message.Code
+	// Lang で書かれたメッセージ
+	Msg  string
+	Lang message.Language
+	// 字句解析できなかった文字
+	Literal string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// ErrorList は字句解析できなかった文字を現れた順に保持する
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err は l が空のときに nil を返し、それ以外は l を error として返す
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Tokenize は src のすべてのトークンを返す
+// 最後の token.EOF は含めない
+// 不正なトークンがあるときも最後まで読み、それらを ErrorList として返す
+func Tokenize(src string, opts ...Option) ([]token.Token, error) {
+	var (
+		ts   []token.Token
+		errs ErrorList
+	)
+	l := New(src, opts...)
+	for t := range l.All() {
+		ts = append(ts, t)
+		if t.Type == token.ILLEGAL {
+			errs = append(errs, &Error{
+				Pos:     t.Pos,
+				End:     t.End(),
+				Code:    message.ErrIllegalCharacter,
+				Msg:     message.Format(l.lang, message.ErrIllegalCharacter, t.Literal),
+				Lang:    l.lang,
+				Literal: t.Literal,
+			})
+		}
+	}
+	return ts, errs.Err()
+}
+
+// Tokens は src のトークンを順に返すイテレータを返す
+// token.EOF は含めない
+func Tokens(src string, opts ...Option) iter.Seq[token.Token] {
+	return New(src, opts...).All()
+}
+
+// All は l の残りのトークンを順に返すイテレータを返す
+// token.EOF は含めない
+func (l *Lexer) All() iter.Seq[token.Token] {
+	return func(yield func(token.Token) bool) {
+		for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+// Lookahead はトークンを消費せずに先読みできるようにする
+type Lookahead struct {
+	l *Lexer
+	// 先読みしたトークン
+	// buf[0] が次に Next で返すトークン
+	buf []token.Token
+}
+
+func NewLookahead(l *Lexer) *Lookahead {
+	return &Lookahead{l: l}
+}
+
+// Peek は n 個先のトークンを消費せずに返す
+// Peek(0) は次に Next で返すトークン
+// 入力の終わりより先は token.EOF になる
+func (la *Lookahead) Peek(n int) token.Token {
+	for len(la.buf) <= n {
+		la.buf = append(la.buf, la.l.NextToken())
+	}
+	return la.buf[n]
+}
+
+// Next は次のトークンを消費して返す
+func (la *Lookahead) Next() token.Token {
+	t := la.Peek(0)
+	la.buf = la.buf[1:]
+	return t
+}
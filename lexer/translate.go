@@ -0,0 +1,47 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Translate は from のキーワードで書かれた src を to のキーワードに書き換える
+// キーワード以外のトークンと、空白やコメントなどトークンの間の文字はそのまま残す
+// 書き換えるとトークンが変わってしまうときはエラーを返す
+// e.g. 識別子 'fn' は標準のキーワードになってしまう
+func Translate(src string, from, to *token.Dialect) (string, error) {
+	var (
+		b      strings.Builder
+		tokens []token.Token
+	)
+	offset := 0
+	for t := range Tokens(src, WithDialect(from)) {
+		start := t.Pos.Offset()
+		b.WriteString(src[offset:start])
+		offset = start + len(t.Literal)
+		tokens = append(tokens, t)
+
+		if w, ok := to.Keyword(t.Type); ok {
+			b.WriteString(w)
+			continue
+		}
+		b.WriteString(t.Literal)
+	}
+	b.WriteString(src[offset:])
+	out := b.String()
+
+	// 書き換えた結果を to で字句解析して、同じ種類のトークンが並ぶことを確かめる
+	l := New(out, WithDialect(to))
+	for _, t := range tokens {
+		tt := l.NextToken()
+		if tt.Type != t.Type {
+			return "", fmt.Errorf("offset %d: %q cannot be written in the %s dialect", t.Pos.Offset(), t.Literal, to)
+		}
+	}
+	if t := l.NextToken(); t.Type != token.EOF {
+		return "", fmt.Errorf("%q cannot be written in the %s dialect", t.Literal, to)
+	}
+	return out, nil
+}
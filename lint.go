@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hiroygo/go-interpreter/lint"
+	"github.com/hiroygo/go-interpreter/parser"
+)
+
+// lintFinding は JSON で出力するときの lint.Finding
+type lintFinding struct {
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Column    int      `json:"column"`
+	EndLine   int      `json:"endLine"`
+	EndColumn int      `json:"endColumn"`
+	Severity  string   `json:"severity"`
+	Rule      string   `json:"rule"`
+	Message   string   `json:"message"`
+	Help      []string `json:"help,omitempty"`
+	Fix       string   `json:"fix,omitempty"`
+}
+
+// 'lint [-format text|json] [-fix] <file>'
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format, text or json")
+	fix := fs.Bool("fix", false, "apply suggested fixes; the fixed script is written back, or to stdout for '-'")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: go-interpreter lint [-format text|json] [-fix] <file>")
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return exitUsage
+	}
+	src, file, err := readSource(fs.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	prg, ok := parseSource(src, file, stderr)
+	if !ok {
+		return exitError
+	}
+	// スクリプトの引数のうち、数が決まっているのは argc だけ
+	findings := lint.Check(prg, src, lint.Config{Globals: []string{"argc"}, Lang: parser.LanguageFromEnv()})
+
+	if *fix {
+		fixed, skipped := lint.ApplyFixes(src, findings)
+		if fs.Arg(0) == "-" {
+			fmt.Fprint(stdout, fixed)
+		} else if err := os.WriteFile(fs.Arg(0), []byte(fixed), 0644); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		if skipped > 0 {
+			fmt.Fprintf(stderr, "%d overlapping fixes were not applied; run lint -fix again\n", skipped)
+		}
+		return exitOK
+	}
+
+	if *format == "json" {
+		out := []lintFinding{}
+		for _, f := range findings {
+			pos, end := file.Position(f.Pos), file.Position(f.End)
+			lf := lintFinding{
+				File:      pos.Filename,
+				Line:      pos.Line,
+				Column:    pos.Column,
+				EndLine:   end.Line,
+				EndColumn: end.Column,
+				Severity:  f.Severity.String(),
+				Rule:      f.Rule,
+				Message:   f.Message,
+				Help:      f.Help,
+			}
+			if f.Fix != nil {
+				lf.Fix = f.Fix.Message
+			}
+			out = append(out, lf)
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Fprintf(stdout, "%s: %s: %s (%s)\n", file.Position(f.Pos), f.Severity, f.Message, f.Rule)
+			for _, h := range f.Help {
+				fmt.Fprintf(stdout, "\thelp: %s\n", h)
+			}
+		}
+	}
+
+	if len(findings) > 0 {
+		return exitError
+	}
+	return exitOK
+}
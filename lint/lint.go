@@ -0,0 +1,220 @@
+// lint はレビューでよく見つかる誤りを AST から検出する
+// 検出する規則は Rule として追加できる
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/parser"
+	"github.com/hiroygo/go-interpreter/resolver"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Finding は規則に違反している箇所を表す
+type Finding struct {
+	Rule     string
+	Pos      token.Pos
+	End      token.Pos
+	Severity Severity
+	Message  string
+	// 直し方の提案
+	// e.g. 綴りの近い名前の候補
+	Help []string
+	// 機械的に適用できる修正
+	// 修正が無いときは nil
+	Fix *Fix
+}
+
+// Fix は 1 つの修正を表す
+// Edits の範囲は互いに重ならない
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
+// Edit は [Pos, End) の範囲を NewText で置き換える
+type Edit struct {
+	Pos     token.Pos
+	End     token.Pos
+	NewText string
+}
+
+// Rule は 1 つの検査を表す
+type Rule interface {
+	// Name は抑制コメントと出力で使う名前を返す
+	// e.g. 'unused'
+	Name() string
+	Check(p *Pass)
+}
+
+// Pass は Rule に解析結果を渡し、Rule から違反を受け取る
+type Pass struct {
+	Program *ast.Program
+	// 識別子と宣言の対応
+	Info *resolver.Info
+	// Finding.Help の言語
+	Lang parser.Language
+
+	rule     Rule
+	findings []Finding
+}
+
+// Report は違反を記録する
+// Rule は空のままでよく、実行中の規則の名前が設定される
+func (p *Pass) Report(f Finding) {
+	f.Rule = p.rule.Name()
+	p.findings = append(p.findings, f)
+}
+
+type Config struct {
+	// 実行する規則
+	// nil のときは DefaultRules を使う
+	Rules []Rule
+	// 組み込みの名前
+	// これらを let で宣言し直すと shadow 規則が報告する
+	Globals []string
+	// Finding.Help の言語
+	// 空のときは英語にする
+	Lang parser.Language
+}
+
+// Check は prg に規則を適用し、違反を位置の順に返す
+// src は抑制コメントを探すために使う
+func Check(prg *ast.Program, src string, cfg Config) []Finding {
+	rules := cfg.Rules
+	if rules == nil {
+		rules = DefaultRules()
+	}
+
+	p := &Pass{Program: prg, Info: resolver.Resolve(prg, cfg.Globals), Lang: cfg.Lang}
+	for _, r := range rules {
+		p.rule = r
+		r.Check(p)
+	}
+
+	file := token.NewFile("", src)
+	ignored := suppressions(src)
+	var fs []Finding
+	for _, f := range p.findings {
+		line := file.Position(f.Pos).Line
+		if ignored.has(line, f.Rule) {
+			continue
+		}
+		fs = append(fs, f)
+	}
+	sort.SliceStable(fs, func(i, j int) bool {
+		return fs[i].Pos < fs[j].Pos
+	})
+	return fs
+}
+
+// 抑制コメントの接頭辞
+// e.g. 'let x = 1; // lint:ignore unused'
+const ignoreDirective = "lint:ignore"
+
+// ignoreSet は行番号ごとに抑制する規則を保持する
+// 規則の名前が '*' のときはすべての規則を抑制する
+type ignoreSet map[int]map[string]bool
+
+func (s ignoreSet) has(line int, rule string) bool {
+	return s[line][rule] || s[line]["*"]
+}
+
+// suppressions は src から抑制コメントを探す
+// コードの後にあるコメントはその行を、コメントだけの行は次の行を抑制する
+// 規則の名前はカンマで区切って複数指定でき、省略するとすべての規則を抑制する
+func suppressions(src string) ignoreSet {
+	s := ignoreSet{}
+	for i, line := range strings.Split(src, "\n") {
+		idx := strings.Index(line, "//")
+		if idx < 0 {
+			continue
+		}
+		comment := strings.TrimSpace(line[idx+2:])
+		if !strings.HasPrefix(comment, ignoreDirective) {
+			continue
+		}
+
+		target := i + 1
+		if strings.TrimSpace(line[:idx]) == "" {
+			target++
+		}
+		if s[target] == nil {
+			s[target] = map[string]bool{}
+		}
+
+		rules := strings.Fields(strings.TrimPrefix(comment, ignoreDirective))
+		if len(rules) == 0 {
+			s[target]["*"] = true
+			continue
+		}
+		for _, r := range strings.Split(rules[0], ",") {
+			s[target][r] = true
+		}
+	}
+	return s
+}
+
+// ApplyFixes は fs の修正を src に適用した結果を返す
+// 他の修正と範囲が重なる修正は適用せず、その数を返す
+func ApplyFixes(src string, fs []Finding) (string, int) {
+	var edits []Edit
+	skipped := 0
+	for _, f := range fs {
+		if f.Fix == nil {
+			continue
+		}
+		if overlaps(edits, f.Fix.Edits) {
+			skipped++
+			continue
+		}
+		edits = append(edits, f.Fix.Edits...)
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Pos < edits[j].Pos
+	})
+
+	var b strings.Builder
+	offset := 0
+	for _, e := range edits {
+		b.WriteString(src[offset:e.Pos.Offset()])
+		b.WriteString(e.NewText)
+		offset = e.End.Offset()
+	}
+	b.WriteString(src[offset:])
+	return b.String(), skipped
+}
+
+func overlaps(applied, edits []Edit) bool {
+	for _, a := range applied {
+		for _, e := range edits {
+			if a.Pos < e.End && e.Pos < a.End {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package lint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/parser"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	prg := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parse %q: %v", input, errs)
+	}
+	return prg
+}
+
+func check(t *testing.T, input string) []string {
+	t.Helper()
+
+	file := token.NewFile("", input)
+	var actual []string
+	for _, f := range Check(parse(t, input), input, Config{Globals: []string{"argc"}}) {
+		actual = append(actual, fmt.Sprintf("%s: %s (%s)", file.Position(f.Pos), f.Message, f.Rule))
+	}
+	return actual
+}
+
+func TestRules(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; x;", nil},
+		{"let x = 1;", []string{"1:5: x is declared but never used (unused)"}},
+		{"let x = 1; x == x;", []string{"1:12: x compared with itself is always true (self-compare)"}},
+		{"let x = 1; (x + 1) < (x + 1);", []string{"1:12: (x + 1) compared with itself is always false (self-compare)"}},
+		{"1 < 2;", []string{"1:1: condition (1 < 2) is always true (constant-condition)"}},
+		{"true != (1 == 1);", []string{"1:1: condition (true != (1 == 1)) is always false (constant-condition)"}},
+		// 算術演算は条件ではない
+		{"1 + 2;", nil},
+		{"argc == 1;", nil},
+		{"!!argc;", []string{"1:1: redundant double negation of argc (double-negation)"}},
+		{"!!!!true;", []string{"1:1: redundant double negation of (!(!true)) (double-negation)"}},
+		{"let argc = 1; argc;", []string{"1:5: declaration of argc shadows builtin argc (shadow)"}},
+		{"retrun 5;", []string{"1:1: undefined: retrun (undefined)"}},
+		// 重複した宣言は実行できるので undefined としては報告しない
+		{"let x = 1; let x = 2; x;", []string{"1:5: x is declared but never used (unused)"}},
+	}
+
+	for _, c := range cases {
+		actual := check(t, c.input)
+		if fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+			t.Errorf("Check(%q):\nwant %q\ngot  %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestUndefinedHelp(t *testing.T) {
+	cases := []struct {
+		input    string
+		lang     parser.Language
+		expected []string
+	}{
+		{"retrun 5;", "", []string{"unknown identifier `retrun`; did you mean keyword `return`?"}},
+		{"let total = 1; totl;", parser.English, []string{"unknown identifier `totl`; did you mean identifier `total`?"}},
+		{"foo;", parser.English, nil},
+	}
+
+	for _, c := range cases {
+		var actual []string
+		for _, f := range Check(parse(t, c.input), c.input, Config{Lang: c.lang}) {
+			if f.Rule == "undefined" {
+				actual = append(actual, f.Help...)
+			}
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+			t.Errorf("Check(%q): want help %q, got %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	input := `let a = 1; // lint:ignore unused
+// lint:ignore
+let b = 1 == 1;
+let c = 1; // lint:ignore shadow,self-compare
+// lint:ignore constant-condition
+let d = 1;
+`
+	expected := []string{
+		"4:5: c is declared but never used (unused)",
+		"6:5: d is declared but never used (unused)",
+	}
+	actual := check(t, input)
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Fatalf("want %q, got %q", expected, actual)
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		skipped  int
+	}{
+		{"let x = 1;\nargc;", "\nargc;", 0},
+		{"argc == argc;", "true;", 0},
+		{"let x = 2; x > x;", "let x = 2; false;", 0},
+		// 実行時のエラーになりうる式は消さない
+		{"let x = 1 / 0;", "let x = 1 / 0;", 0},
+		{"let x = -true;", "let x = -true;", 0},
+		{"let x = 1 == true;", "let x = 1 == true;", 0},
+		{"let x = (3 * -2) / (2);\nargc;", "\nargc;", 0},
+		{"(1 / argc) == (1 / argc);", "(1 / argc) == (1 / argc);", 0},
+		// 整数ではないかもしれない値の大小は比べられない
+		{"argc > argc;", "argc > argc;", 0},
+		{"let b = true; b < b;", "let b = true; b < b;", 0},
+		{"1 == 2;", "false;", 0},
+		{"!!(argc < 1);", "(argc < 1);", 0},
+		// 真偽値とは限らない式は修正しない
+		{"!!argc;", "!!argc;", 0},
+		// 宣言の削除と範囲が重なる修正は適用しない
+		{"let x = 1 == 1;", "", 1},
+	}
+
+	for _, c := range cases {
+		fs := Check(parse(t, c.input), c.input, Config{Globals: []string{"argc"}})
+		actual, skipped := ApplyFixes(c.input, fs)
+		if actual != c.expected || skipped != c.skipped {
+			t.Errorf("ApplyFixes(%q): want (%q, %d), got (%q, %d)", c.input, c.expected, c.skipped, actual, skipped)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/evaluator"
+	"github.com/hiroygo/go-interpreter/object"
+	"github.com/hiroygo/go-interpreter/parser"
+	"github.com/hiroygo/go-interpreter/resolver"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// DefaultRules は Config.Rules が nil のときに実行する規則を返す
+func DefaultRules() []Rule {
+	return []Rule{
+		unusedRule{},
+		selfCompareRule{},
+		constantConditionRule{},
+		doubleNegationRule{},
+		shadowRule{},
+		undefinedRule{},
+	}
+}
+
+// unusedRule は一度も使われていない let の束縛を報告する
+type unusedRule struct{}
+
+func (unusedRule) Name() string {
+	return "unused"
+}
+
+func (unusedRule) Check(p *Pass) {
+	used := map[*ast.Identifier]bool{}
+	for _, ref := range p.Info.Uses {
+		if ref.Decl != nil && ref.Decl.Ident != nil {
+			used[ref.Decl.Ident] = true
+		}
+	}
+
+	for _, s := range p.Program.Statements {
+		let, ok := s.(*ast.LetStatement)
+		if !ok || let.Name == nil || used[let.Name] {
+			continue
+		}
+		f := Finding{
+			Pos:      let.Name.Pos(),
+			End:      let.Name.End(),
+			Severity: Warning,
+			Message:  fmt.Sprintf("%s is declared but never used", let.Name.Value),
+		}
+		// '1 / 0' のようにエラーになる式を消すと、実行時のエラーも消えてしまう
+		if _, ok := p.typeOf(let.Value); ok {
+			f.Fix = &Fix{
+				Message: fmt.Sprintf("remove the declaration of %s", let.Name.Value),
+				Edits:   []Edit{{Pos: let.Pos(), End: let.End()}},
+			}
+		}
+		p.Report(f)
+	}
+}
+
+// selfCompareRule は 'x == x' のように同じ式を比較しているものを報告する
+type selfCompareRule struct{}
+
+func (selfCompareRule) Name() string {
+	return "self-compare"
+}
+
+// 同じ値同士を比較したときの結果
+var selfCompareResults = map[string]string{
+	"==": "true",
+	"!=": "false",
+	"<":  "false",
+	">":  "false",
+}
+
+func (selfCompareRule) Check(p *Pass) {
+	ast.Inspect(p.Program, func(n ast.Node) bool {
+		infix, ok := n.(*ast.InfixExpression)
+		if !ok || infix.Left == nil || infix.Right == nil {
+			return true
+		}
+		result, ok := selfCompareResults[infix.Operator]
+		if !ok || infix.Left.String() != infix.Right.String() {
+			return true
+		}
+		// 両辺がリテラルのときは constant-condition が報告する
+		if isLiteral(infix.Left) {
+			return true
+		}
+		f := Finding{
+			Pos:      infix.Pos(),
+			End:      infix.End(),
+			Severity: Warning,
+			Message:  fmt.Sprintf("%s compared with itself is always %s", infix.Left, result),
+		}
+		// 比較がエラーになりうるときは置き換えない
+		// e.g. '(1 / x) == (1 / x)', 'true < true'
+		if typ, ok := p.typeOf(infix.Left); ok && (typ == object.INTEGER_OBJ || !isOrdering(infix.Operator)) {
+			f.Fix = &Fix{
+				Message: fmt.Sprintf("replace with %s", result),
+				Edits:   []Edit{{Pos: infix.Pos(), End: infix.End(), NewText: result}},
+			}
+		}
+		p.Report(f)
+		return true
+	})
+}
+
+// constantConditionRule はリテラル同士の比較のように、結果が常に同じになる条件を報告する
+// e.g. '1 < 2', 'true == false'
+type constantConditionRule struct{}
+
+func (constantConditionRule) Name() string {
+	return "constant-condition"
+}
+
+func (constantConditionRule) Check(p *Pass) {
+	ast.Inspect(p.Program, func(n ast.Node) bool {
+		infix, ok := n.(*ast.InfixExpression)
+		if !ok || !isComparison(infix.Operator) || !isConstant(infix.Left) || !isConstant(infix.Right) {
+			return true
+		}
+		// 定数だけの式なので、評価すれば結果がわかる
+		b, ok := evaluator.Eval(infix, object.NewEnvironment()).(*object.Boolean)
+		if !ok {
+			return true
+		}
+		result := fmt.Sprintf("%t", b.Value)
+		p.Report(Finding{
+			Pos:      infix.Pos(),
+			End:      infix.End(),
+			Severity: Warning,
+			Message:  fmt.Sprintf("condition %s is always %s", infix, result),
+			Fix: &Fix{
+				Message: fmt.Sprintf("replace with %s", result),
+				Edits:   []Edit{{Pos: infix.Pos(), End: infix.End(), NewText: result}},
+			},
+		})
+		// 内側の比較を重ねて報告しない
+		return false
+	})
+}
+
+// doubleNegationRule は '!!x' を報告する
+// x が真偽値になる式のときは x に置き換える修正を付ける
+type doubleNegationRule struct{}
+
+func (doubleNegationRule) Name() string {
+	return "double-negation"
+}
+
+func (doubleNegationRule) Check(p *Pass) {
+	ast.Inspect(p.Program, func(n ast.Node) bool {
+		outer, ok := n.(*ast.PrefixExpression)
+		if !ok || outer.Operator != "!" {
+			return true
+		}
+		inner, ok := outer.Right.(*ast.PrefixExpression)
+		if !ok || inner.Operator != "!" || inner.Right == nil {
+			return true
+		}
+
+		f := Finding{
+			Pos:      outer.Pos(),
+			End:      outer.End(),
+			Severity: Warning,
+			Message:  fmt.Sprintf("redundant double negation of %s", inner.Right),
+		}
+		// '!!5' は true なので、真偽値ではない式は置き換えられない
+		if isBoolean(inner.Right) {
+			f.Fix = &Fix{
+				Message: fmt.Sprintf("replace with %s", inner.Right),
+				Edits: []Edit{
+					{Pos: outer.Pos(), End: inner.Right.Pos()},
+				},
+			}
+		}
+		p.Report(f)
+		// '!!!!x' を重ねて報告しない
+		return false
+	})
+}
+
+// shadowRule は外側のスコープや組み込みの名前を隠す宣言を報告する
+// resolver の警告をそのまま使い、同じ判定を繰り返さない
+type shadowRule struct{}
+
+func (shadowRule) Name() string {
+	return "shadow"
+}
+
+func (shadowRule) Check(p *Pass) {
+	// resolver が警告するのはシャドーイングだけ
+	for _, d := range p.Info.Diagnostics {
+		if d.Severity != resolver.Warning {
+			continue
+		}
+		p.Report(Finding{
+			Pos:      d.Pos,
+			End:      d.End,
+			Severity: Warning,
+			Message:  d.Msg,
+		})
+	}
+}
+
+// undefinedRule は宣言されていない名前の使用を、resolver の候補と一緒に報告する
+// e.g. 'retrun 5;' は 'retrun' と '5' の 2 つの文として構文解析できる
+type undefinedRule struct{}
+
+func (undefinedRule) Name() string {
+	return "undefined"
+}
+
+func (undefinedRule) Check(p *Pass) {
+	undefined := map[token.Pos]bool{}
+	for ident, ref := range p.Info.Uses {
+		if ref.Kind == resolver.Undefined {
+			undefined[ident.Pos()] = true
+		}
+	}
+	for _, d := range p.Info.Diagnostics {
+		if d.Severity != resolver.Error || !undefined[d.Pos] {
+			continue
+		}
+		f := Finding{
+			Pos:      d.Pos,
+			End:      d.End,
+			Severity: Error,
+			Message:  d.Msg,
+		}
+		for _, h := range d.Hints {
+			f.Help = append(f.Help, parser.HintMessage(p.Lang, h))
+		}
+		p.Report(f)
+	}
+}
+
+func isComparison(op string) bool {
+	_, ok := selfCompareResults[op]
+	return ok
+}
+
+// isOrdering は op が整数にしか使えない比較のときに true を返す
+func isOrdering(op string) bool {
+	return op == "<" || op == ">"
+}
+
+func isLiteral(e ast.Expression) bool {
+	switch e.(type) {
+	case *ast.IntegerLiteral, *ast.Boolean:
+		return true
+	}
+	return false
+}
+
+// isConstant は e が識別子を含まないときに true を返す
+func isConstant(e ast.Expression) bool {
+	if e == nil {
+		return false
+	}
+	constant := true
+	ast.Inspect(e, func(n ast.Node) bool {
+		if _, ok := n.(*ast.Identifier); ok {
+			constant = false
+		}
+		return constant
+	})
+	return constant
+}
+
+// isBoolean は e の値が必ず真偽値になるときに true を返す
+func isBoolean(e ast.Expression) bool {
+	switch e := e.(type) {
+	case *ast.Boolean:
+		return true
+	case *ast.GroupedExpression:
+		return isBoolean(e.Expression)
+	case *ast.PrefixExpression:
+		return e.Operator == "!"
+	case *ast.InfixExpression:
+		return isComparison(e.Operator)
+	}
+	return false
+}
+
+// typeOf は e を評価したときの値の型を返す
+// 評価がエラーになりうるときは ok が false になる
+// 識別子のように型がわからないときは、型が空になる
+func (p *Pass) typeOf(e ast.Expression) (typ object.ObjectType, ok bool) {
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		return object.INTEGER_OBJ, true
+	case *ast.Boolean:
+		return object.BOOLEAN_OBJ, true
+	case *ast.GroupedExpression:
+		return p.typeOf(e.Expression)
+	case *ast.Identifier:
+		ref := p.Info.Uses[e]
+		if ref == nil || ref.Decl == nil {
+			return "", false
+		}
+		// 宣言した let 文の右辺から型を求める
+		if let, isLet := ref.Decl.Node.(*ast.LetStatement); isLet {
+			typ, _ := p.typeOf(let.Value)
+			return typ, true
+		}
+		return "", true
+	case *ast.PrefixExpression:
+		right, ok := p.typeOf(e.Right)
+		switch {
+		case !ok:
+			return "", false
+		case e.Operator == "!":
+			return object.BOOLEAN_OBJ, true
+		case e.Operator == "-" && right == object.INTEGER_OBJ:
+			return object.INTEGER_OBJ, true
+		}
+	case *ast.InfixExpression:
+		left, lok := p.typeOf(e.Left)
+		right, rok := p.typeOf(e.Right)
+		if !lok || !rok || left == "" || left != right {
+			return "", false
+		}
+		switch {
+		case isComparison(e.Operator) && (left == object.INTEGER_OBJ || !isOrdering(e.Operator)):
+			return object.BOOLEAN_OBJ, true
+		case left != object.INTEGER_OBJ:
+			return "", false
+		case e.Operator == "+", e.Operator == "-", e.Operator == "*":
+			return object.INTEGER_OBJ, true
+		case e.Operator == "/":
+			// 右辺が 0 ではない整数リテラルのときだけゼロ除算にならない
+			if lit, isLit := unparen(e.Right).(*ast.IntegerLiteral); isLit && lit.Value != 0 {
+				return object.INTEGER_OBJ, true
+			}
+		}
+	}
+	return "", false
+}
+
+// unparen は括弧を取り除いた式を返す
+func unparen(e ast.Expression) ast.Expression {
+	for {
+		g, ok := e.(*ast.GroupedExpression)
+		if !ok {
+			return e
+		}
+		e = g.Expression
+	}
+}
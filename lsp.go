@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hiroygo/go-interpreter/lsp"
+)
+
+// 'lsp'
+// エディタから起動され、標準入出力で Language Server Protocol を話す
+func runLSP(stdin io.Reader, stdout, stderr io.Writer) int {
+	if err := lsp.NewServer(stdin, stdout).Run(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
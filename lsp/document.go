@@ -0,0 +1,235 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/parser"
+	"github.com/hiroygo/go-interpreter/resolver"
+	"github.com/hiroygo/go-interpreter/suggest"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// document は開いているファイルと、その解析結果を保持する
+// 変更のたびに全体を解析し直す
+type document struct {
+	uri     string
+	version int
+	text    string
+	file    *token.File
+	program *ast.Program
+	errors  parser.ErrorList
+	info    *resolver.Info
+	dialect *token.Dialect
+}
+
+func newDocument(uri string, version int, text string, dialect *token.Dialect) *document {
+	p := parser.New(lexer.New(text, lexer.WithDialect(dialect)))
+	prg := p.ParseProgram()
+	return &document{
+		uri:     uri,
+		version: version,
+		text:    text,
+		dialect: dialect,
+		file:    token.NewFile(uri, text),
+		program: prg,
+		errors:  p.ErrorList(),
+		// スクリプトの引数のうち、数が決まっているのは argc だけ
+		info: resolver.Resolve(prg, []string{"argc"}),
+	}
+}
+
+// position は p を LSP の位置に変換する
+func (d *document) position(p token.Pos) Position {
+	pos := d.file.Position(p)
+	if !pos.IsValid() {
+		return Position{}
+	}
+	start := d.file.LineStart(pos.Line)
+	return Position{Line: pos.Line - 1, Character: utf16Len(d.text[start:pos.Offset])}
+}
+
+func (d *document) rangeOf(pos, end token.Pos) Range {
+	return Range{Start: d.position(pos), End: d.position(end)}
+}
+
+// pos は LSP の位置を token.Pos に変換する
+// 行の長さを超える位置は行末に丸める
+func (d *document) pos(p Position) token.Pos {
+	start := d.file.LineStart(p.Line + 1)
+	if start < 0 {
+		return token.PosFromOffset(len(d.text))
+	}
+	offset, units := start, 0
+	for offset < len(d.text) && d.text[offset] != '\n' && units < p.Character {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		units += utf16RuneLen(r)
+		offset += size
+	}
+	return token.PosFromOffset(offset)
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+// サロゲートペアになる文字は 2 つのコード単位を使う
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// diagnostics は構文エラーと、resolver の診断を返す
+// 構文エラーがあるときは AST が欠けているので、resolver の診断は含めない
+func (d *document) diagnostics() []Diagnostic {
+	ds := []Diagnostic{}
+	for _, e := range d.errors {
+		ds = append(ds, Diagnostic{
+			Range:    d.rangeOf(e.Pos, e.End),
+			Severity: SeverityError,
+			Code:     string(e.Code),
+			Source:   "go-interpreter",
+			Message:  withHints(e.Msg, e.Lang, e.Hints),
+		})
+	}
+	if len(d.errors) != 0 {
+		return ds
+	}
+	for _, r := range d.info.Diagnostics {
+		sev := SeverityError
+		if r.Severity == resolver.Warning {
+			sev = SeverityWarning
+		}
+		ds = append(ds, Diagnostic{
+			Range:    d.rangeOf(r.Pos, r.End),
+			Severity: sev,
+			Source:   "go-interpreter",
+			Message:  withHints(r.Msg, parser.English, r.Hints),
+		})
+	}
+	return ds
+}
+
+// withHints は名前の候補を 1 行ずつ msg に続ける
+func withHints(msg string, lang parser.Language, hints []suggest.Hint) string {
+	for _, h := range hints {
+		msg += "\n" + parser.HintMessage(lang, h)
+	}
+	return msg
+}
+
+// symbols は let で束縛した名前を返す
+func (d *document) symbols() []DocumentSymbol {
+	ss := []DocumentSymbol{}
+	for _, s := range d.program.Statements {
+		let, ok := s.(*ast.LetStatement)
+		if !ok || let.Name == nil {
+			continue
+		}
+		var detail string
+		if let.Value != nil {
+			detail = let.Value.String()
+		}
+		ss = append(ss, DocumentSymbol{
+			Name:           let.Name.Value,
+			Detail:         detail,
+			Kind:           SymbolKindVariable,
+			Range:          d.rangeOf(let.Pos(), let.End()),
+			SelectionRange: d.rangeOf(let.Name.Pos(), let.Name.End()),
+		})
+	}
+	return ss
+}
+
+// hover は p にある最も内側のノードの種類を返す
+func (d *document) hover(p Position) *Hover {
+	n := ast.NodeAt(d.program, d.pos(p))
+	if n == nil {
+		return nil
+	}
+	kind := strings.TrimPrefix(fmt.Sprintf("%T", n), "*")
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: fmt.Sprintf("**%s**\n\n```\n%s\n```", kind, n.String()),
+		},
+		Range: d.rangeOf(n.Pos(), n.End()),
+	}
+}
+
+// definition は p にある識別子を宣言した場所を返す
+func (d *document) definition(p Position) *Location {
+	ident, ok := ast.NodeAt(d.program, d.pos(p)).(*ast.Identifier)
+	if !ok {
+		return nil
+	}
+
+	var decl *resolver.Decl
+	if def, ok := d.info.Defs[ident]; ok {
+		decl = def
+	} else if ref, ok := d.info.Uses[ident]; ok {
+		decl = ref.Decl
+	}
+	if decl == nil || decl.Ident == nil {
+		return nil
+	}
+	return &Location{URI: d.uri, Range: d.rangeOf(decl.Ident.Pos(), decl.Ident.End())}
+}
+
+// semanticTokenTypes の添字がトークンの種類を表す
+var semanticTokenTypes = []string{"keyword", "variable", "number", "operator"}
+
+const (
+	semanticKeyword = iota
+	semanticVariable
+	semanticNumber
+	semanticOperator
+)
+
+// semanticType は t の LSP の種類を返す
+// キーワードかどうかは d の綴りで判定する
+func semanticType(t token.Token, d *token.Dialect) (int, bool) {
+	switch t.Type {
+	case token.IDENT:
+		return semanticVariable, true
+	case token.INT:
+		return semanticNumber, true
+	case token.ASSIGN, token.PLUS, token.MINUS, token.BANG, token.ASTERISK,
+		token.SLASH, token.LT, token.GT, token.EQ, token.NOT_EQ:
+		return semanticOperator, true
+	}
+	if d.LookupIdent(t.Literal) != token.IDENT {
+		return semanticKeyword, true
+	}
+	return 0, false
+}
+
+// semanticTokens は字句解析したトークンを LSP の相対位置の形式で返す
+// 区切り記号と不正なトークンは含めない
+func (d *document) semanticTokens() []int {
+	data := []int{}
+	var prev Position
+	for t := range lexer.Tokens(d.text, lexer.WithDialect(d.dialect)) {
+		typ, ok := semanticType(t, d.dialect)
+		if !ok {
+			continue
+		}
+		p := d.position(t.Pos)
+		deltaStart := p.Character
+		if p.Line == prev.Line {
+			deltaStart -= prev.Character
+		}
+		data = append(data, p.Line-prev.Line, deltaStart, utf16Len(t.Literal), typ, 0)
+		prev = p
+	}
+	return data
+}
@@ -0,0 +1,238 @@
+// lsp は Language Server Protocol のサーバーを実装する
+// 標準入出力で JSON-RPC 2.0 のメッセージをやり取りする
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// ErrExitWithoutShutdown は shutdown を受け取る前に exit を受け取ったときに Run が返す
+// 仕様ではこのときの終了コードを 1 にする
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown")
+
+// Server は 1 つのクライアントとやり取りする
+// メッセージは届いた順に 1 つずつ処理する
+type Server struct {
+	conn        *conn
+	docs        map[string]*document
+	dialect     *token.Dialect
+	initialized bool
+	shutdown    bool
+}
+
+type Option func(*Server)
+
+// WithDialect は開いたファイルのキーワードの綴りを d にする
+func WithDialect(d *token.Dialect) Option {
+	return func(s *Server) {
+		s.dialect = d
+	}
+}
+
+func NewServer(in io.Reader, out io.Writer, opts ...Option) *Server {
+	s := &Server{conn: newConn(in, out), docs: map[string]*document{}, dialect: token.Standard}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run は exit 通知を受け取るか入力が終わるまでメッセージを処理する
+func (s *Server) Run() error {
+	for {
+		body, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.replyError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(&req); err != nil {
+			return err
+		}
+	}
+}
+
+// handle は 1 つのメッセージを処理する
+// 返すエラーは書き込みの失敗だけで、リクエストの失敗はエラーレスポンスとして返す
+func (s *Server) handle(req *request) error {
+	if req.isNotification() {
+		return s.notify(req)
+	}
+
+	if !s.initialized && req.Method != "initialize" {
+		return s.replyError(req.ID, codeServerNotInitialized, "server not initialized")
+	}
+	if s.shutdown {
+		return s.replyError(req.ID, codeInvalidRequest, "server is shutting down")
+	}
+
+	var (
+		result interface{}
+		rerr   *responseError
+	)
+	switch req.Method {
+	case "initialize":
+		s.initialized = true
+		result = s.initialize()
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if rerr = decode(req.Params, &params); rerr == nil {
+			result, rerr = s.withDocument(params.TextDocument.URI, func(d *document) interface{} {
+				return d.symbols()
+			})
+		}
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if rerr = decode(req.Params, &params); rerr == nil {
+			result, rerr = s.withDocument(params.TextDocument.URI, func(d *document) interface{} {
+				if h := d.hover(params.Position); h != nil {
+					return h
+				}
+				return nil
+			})
+		}
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if rerr = decode(req.Params, &params); rerr == nil {
+			result, rerr = s.withDocument(params.TextDocument.URI, func(d *document) interface{} {
+				if l := d.definition(params.Position); l != nil {
+					return l
+				}
+				return nil
+			})
+		}
+	case "textDocument/semanticTokens/full":
+		var params SemanticTokensParams
+		if rerr = decode(req.Params, &params); rerr == nil {
+			result, rerr = s.withDocument(params.TextDocument.URI, func(d *document) interface{} {
+				return SemanticTokens{Data: d.semanticTokens()}
+			})
+		}
+	default:
+		rerr = &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+
+	if rerr != nil {
+		return s.replyError(req.ID, rerr.Code, rerr.Message)
+	}
+	return s.reply(req.ID, result)
+}
+
+// notify は通知を処理する
+// 知らない通知と初期化前の通知は無視する
+func (s *Server) notify(req *request) error {
+	if !s.initialized {
+		return nil
+	}
+
+	switch req.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if decode(req.Params, &params) != nil {
+			return nil
+		}
+		item := params.TextDocument
+		return s.update(newDocument(item.URI, item.Version, item.Text, s.dialect))
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if decode(req.Params, &params) != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		// 全体を送ってもらっているので、最後の変更が最新の内容になる
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.update(newDocument(params.TextDocument.URI, params.TextDocument.Version, text, s.dialect))
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if decode(req.Params, &params) != nil {
+			return nil
+		}
+		delete(s.docs, params.TextDocument.URI)
+		// 閉じたファイルの診断を消す
+		return s.publish(PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+	}
+	return nil
+}
+
+func (s *Server) initialize() InitializeResult {
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:       1,
+			HoverProvider:          true,
+			DefinitionProvider:     true,
+			DocumentSymbolProvider: true,
+			SemanticTokensProvider: SemanticTokensOptions{
+				Legend: SemanticTokensLegend{TokenTypes: semanticTokenTypes, TokenModifiers: []string{}},
+				Full:   true,
+			},
+		},
+		ServerInfo: ServerInfo{Name: "go-interpreter"},
+	}
+}
+
+// update は d を保存し、入力のたびに診断を送る
+func (s *Server) update(d *document) error {
+	s.docs[d.uri] = d
+	return s.publish(PublishDiagnosticsParams{URI: d.uri, Version: d.version, Diagnostics: d.diagnostics()})
+}
+
+func (s *Server) publish(params PublishDiagnosticsParams) error {
+	return s.conn.write(&notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  params,
+	})
+}
+
+func (s *Server) withDocument(uri string, f func(*document) interface{}) (interface{}, *responseError) {
+	d, ok := s.docs[uri]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown document: %s", uri)}
+	}
+	return f(d), nil
+}
+
+func decode(params json.RawMessage, v interface{}) *responseError {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return s.replyError(id, codeInternalError, err.Error())
+	}
+	raw := json.RawMessage(b)
+	return s.conn.write(&response{JSONRPC: "2.0", ID: id, Result: &raw})
+}
+
+func (s *Server) replyError(id *json.RawMessage, code int, msg string) error {
+	return s.conn.write(&response{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &responseError{Code: code, Message: msg},
+	})
+}
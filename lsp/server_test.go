@@ -0,0 +1,280 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+const uri = "file:///test.mk"
+
+// script は JSON-RPC のメッセージを順番に並べた入力を作る
+func script(msgs ...string) io.Reader {
+	var b bytes.Buffer
+	for _, m := range msgs {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+	return &b
+}
+
+func call(id int, method string, params string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":%s}`, id, method, params)
+}
+
+func notify(method string, params string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","method":%q,"params":%s}`, method, params)
+}
+
+// run は入力をすべて処理し、出力されたメッセージを順番に返す
+func run(t *testing.T, msgs ...string) ([]map[string]json.RawMessage, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := NewServer(script(msgs...), &out).Run()
+
+	var replies []map[string]json.RawMessage
+	c := newConn(&out, nil)
+	for {
+		body, rerr := c.read()
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			t.Fatal(rerr)
+		}
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatal(err)
+		}
+		replies = append(replies, m)
+	}
+	return replies, err
+}
+
+func open(text string) string {
+	b, _ := json.Marshal(text)
+	return notify("textDocument/didOpen",
+		fmt.Sprintf(`{"textDocument":{"uri":%q,"languageId":"monkey","version":1,"text":%s}}`, uri, b))
+}
+
+func at(line, character int) string {
+	return fmt.Sprintf(`{"textDocument":{"uri":%q},"position":{"line":%d,"character":%d}}`, uri, line, character)
+}
+
+func compact(t *testing.T, raw json.RawMessage) string {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestLifecycle(t *testing.T) {
+	replies, err := run(t,
+		call(1, "textDocument/hover", at(0, 0)),
+		call(2, "initialize", `{"capabilities":{}}`),
+		notify("initialized", `{}`),
+		call(3, "unknown/method", `{}`),
+		call(4, "shutdown", `null`),
+		notify("exit", `null`),
+	)
+	if err != nil {
+		t.Fatalf("want Run() = nil, got %v", err)
+	}
+	if len(replies) != 4 {
+		t.Fatalf("want 4 replies, got %d", len(replies))
+	}
+	if !strings.Contains(string(replies[0]["error"]), "-32002") {
+		t.Fatalf("want ServerNotInitialized before initialize, got %s", replies[0]["error"])
+	}
+	if !strings.Contains(string(replies[1]["result"]), `"hoverProvider":true`) {
+		t.Fatalf("want capabilities, got %s", replies[1]["result"])
+	}
+	if !strings.Contains(string(replies[2]["error"]), "-32601") {
+		t.Fatalf("want MethodNotFound, got %s", replies[2]["error"])
+	}
+	if string(replies[3]["result"]) != "null" {
+		t.Fatalf("want shutdown result null, got %s", replies[3]["result"])
+	}
+
+	_, err = run(t, call(1, "initialize", `{}`), notify("exit", `null`))
+	if err != ErrExitWithoutShutdown {
+		t.Fatalf("want Run() = ErrExitWithoutShutdown, got %v", err)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	change := notify("textDocument/didChange",
+		fmt.Sprintf(`{"textDocument":{"uri":%q,"version":2},"contentChanges":[{"text":"let x = 1;"}]}`, uri))
+	replies, _ := run(t,
+		call(1, "initialize", `{}`),
+		open("let x = 1;\nlet y 2;"),
+		change,
+	)
+	if len(replies) != 3 {
+		t.Fatalf("want 3 messages, got %d", len(replies))
+	}
+
+	expected := `{"uri":"file:///test.mk","version":1,"diagnostics":[` +
+		`{"range":{"start":{"line":1,"character":6},"end":{"line":1,"character":7}},` +
+		`"severity":1,"code":"E0001","source":"go-interpreter","message":"expected next token to be \"=\", got \"INT\" instead"}]}`
+	if actual := compact(t, replies[1]["params"]); actual != expected {
+		t.Fatalf("want didOpen diagnostics %s, got %s", expected, actual)
+	}
+
+	// 修正すると診断が空になる
+	expected = `{"uri":"file:///test.mk","version":2,"diagnostics":[]}`
+	if actual := compact(t, replies[2]["params"]); actual != expected {
+		t.Fatalf("want didChange diagnostics %s, got %s", expected, actual)
+	}
+}
+
+func TestResolverDiagnostics(t *testing.T) {
+	replies, _ := run(t,
+		call(1, "initialize", `{}`),
+		open("retrun 5;\nlet argc = 1;"),
+	)
+	if len(replies) != 2 {
+		t.Fatalf("want 2 messages, got %d", len(replies))
+	}
+
+	expected := `{"uri":"file:///test.mk","version":1,"diagnostics":[` +
+		`{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":6}},` +
+		`"severity":1,"source":"go-interpreter","message":"undefined: retrun\nunknown identifier ` + "`retrun`; did you mean keyword `return`?" + `"},` +
+		`{"range":{"start":{"line":1,"character":4},"end":{"line":1,"character":8}},` +
+		`"severity":2,"source":"go-interpreter","message":"declaration of argc shadows builtin argc"}]}`
+	if actual := compact(t, replies[1]["params"]); actual != expected {
+		t.Fatalf("want diagnostics %s, got %s", expected, actual)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	// 'é' は UTF-16 で 1 コード単位、'😀' は 2 コード単位になる
+	text := "let a = 1;\nlet b = a + 2; é😀\na * b;"
+	replies, _ := run(t,
+		call(1, "initialize", `{}`),
+		open(text),
+		call(2, "textDocument/documentSymbol", fmt.Sprintf(`{"textDocument":{"uri":%q}}`, uri)),
+		call(3, "textDocument/hover", at(1, 10)),
+		call(4, "textDocument/definition", at(2, 4)),
+		call(5, "textDocument/definition", at(1, 2)),
+		call(6, "textDocument/semanticTokens/full", fmt.Sprintf(`{"textDocument":{"uri":%q}}`, uri)),
+	)
+	// 1 つ目は initialize、2 つ目は didOpen の診断
+	results := map[int]string{}
+	for _, r := range replies[2:] {
+		var id int
+		if err := json.Unmarshal(r["id"], &id); err != nil {
+			t.Fatal(err)
+		}
+		results[id] = compact(t, r["result"])
+	}
+
+	cases := []struct {
+		id       int
+		expected string
+	}{
+		{2, `[{"name":"a","detail":"1","kind":13,` +
+			`"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":10}},` +
+			`"selectionRange":{"start":{"line":0,"character":4},"end":{"line":0,"character":5}}},` +
+			`{"name":"b","detail":"(a + 2)","kind":13,` +
+			`"range":{"start":{"line":1,"character":0},"end":{"line":1,"character":14}},` +
+			`"selectionRange":{"start":{"line":1,"character":4},"end":{"line":1,"character":5}}}]`},
+		{3, `{"contents":{"kind":"markdown","value":"**ast.InfixExpression**\n\n` + "```" + `\n(a + 2)\n` + "```" + `"},` +
+			`"range":{"start":{"line":1,"character":8},"end":{"line":1,"character":13}}}`},
+		// 'a * b' の b から 'let b' へ
+		{4, `{"uri":"file:///test.mk","range":{"start":{"line":1,"character":4},"end":{"line":1,"character":5}}}`},
+		// 'let' の上には識別子が無い
+		{5, `null`},
+	}
+	for _, c := range cases {
+		if results[c.id] != c.expected {
+			t.Errorf("want result %d = %s, got %s", c.id, c.expected, results[c.id])
+		}
+	}
+
+	var tokens SemanticTokens
+	if err := json.Unmarshal([]byte(results[6]), &tokens); err != nil {
+		t.Fatal(err)
+	}
+	// 最初の 2 つは 'let' と 'a'
+	expectedPrefix := []int{0, 0, 3, semanticKeyword, 0, 0, 4, 1, semanticVariable, 0}
+	if fmt.Sprint(tokens.Data[:len(expectedPrefix)]) != fmt.Sprint(expectedPrefix) {
+		t.Errorf("want semantic tokens to start with %v, got %v", expectedPrefix, tokens.Data)
+	}
+	// 3 行目の 'a' は前の行から 1 行下の先頭
+	last := tokens.Data[len(tokens.Data)-15:]
+	if fmt.Sprint(last[:5]) != fmt.Sprint([]int{1, 0, 1, semanticVariable, 0}) {
+		t.Errorf("want 'a' on line 3 encoded as [1 0 1 1 0], got %v", last[:5])
+	}
+}
+
+func TestSemanticTokensDialect(t *testing.T) {
+	d := newDocument(uri, 1, "変数 x = 真;", token.Japanese)
+	expected := []int{
+		0, 0, 2, semanticKeyword, 0,
+		0, 3, 1, semanticVariable, 0,
+		0, 2, 1, semanticOperator, 0,
+		0, 2, 1, semanticKeyword, 0,
+	}
+	if actual := d.semanticTokens(); fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Fatalf("want semantic tokens %v, got %v", expected, actual)
+	}
+	if len(d.errors) != 0 {
+		t.Fatalf("want the document parsed with the dialect, got %v", d.errors)
+	}
+}
+
+func TestPositionConversion(t *testing.T) {
+	d := newDocument(uri, 1, "x😀y\nz", token.Standard)
+	cases := []struct {
+		offset int
+		pos    Position
+	}{
+		{0, Position{0, 0}},
+		{1, Position{0, 1}},
+		{5, Position{0, 3}},
+		{7, Position{1, 0}},
+	}
+	for _, c := range cases {
+		p := d.position(token.PosFromOffset(c.offset))
+		if p != c.pos {
+			t.Errorf("want position(%d) = %+v, got %+v", c.offset, c.pos, p)
+		}
+		if back := d.pos(c.pos).Offset(); back != c.offset {
+			t.Errorf("want pos(%+v) = %d, got %d", c.pos, c.offset, back)
+		}
+	}
+}
+
+// 入力中のソースは式が欠けているので、子ノードが nil の AST でも応答する
+func TestIncompleteExpressions(t *testing.T) {
+	replies, _ := run(t,
+		call(1, "initialize", `{}`),
+		open("let x = 1 + ;\nlet y = -;\nlet z = (;"),
+		call(2, "textDocument/documentSymbol", fmt.Sprintf(`{"textDocument":{"uri":%q}}`, uri)),
+		call(3, "textDocument/hover", at(0, 10)),
+		call(4, "textDocument/hover", at(1, 8)),
+	)
+	if len(replies) != 5 {
+		t.Fatalf("want 5 replies, got %d", len(replies))
+	}
+	symbols := compact(t, replies[2]["result"])
+	for _, want := range []string{`"name":"x","detail":"(1 + )"`, `"name":"y","detail":"(-)"`} {
+		if !strings.Contains(symbols, want) {
+			t.Errorf("want symbols to contain %s, got %s", want, symbols)
+		}
+	}
+	for _, r := range replies[3:] {
+		if !strings.Contains(string(r["result"]), "ast.") {
+			t.Errorf("want a hover for the incomplete expression, got %s", r["result"])
+		}
+	}
+}
@@ -2,18 +2,82 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 
 	"github.com/hiroygo/go-interpreter/repl"
 )
 
+const usage = `usage:
+	go-interpreter                            start the REPL
+	go-interpreter run <file> [args...]       run a script
+	go-interpreter tokens <file>              print the tokens of a script
+	go-interpreter parse <file>               print the AST of a script
+	go-interpreter diff <old> <new>           print the structural diff of two scripts
+	go-interpreter serve [flags] <address>    serve REPL sessions on a TCP or Unix socket
+	go-interpreter lsp                        run the language server on stdin and stdout
+	go-interpreter highlight [flags] <file>   print a script with syntax highlighting
+	go-interpreter lint [flags] <file>        report suspicious code in a script
+	go-interpreter explain <code>             explain an error code such as E0003
+
+<file> may be '-' to read the script from stdin.
+Script arguments must be integers and are bound to arg1, arg2, ... and argc.`
+
+// 終了コード
+const (
+	exitOK = 0
+	// スクリプトの構文エラーや実行時エラー
+	exitError = 1
+	// 引数の誤りやファイルを読めないとき
+	exitUsage = 2
+)
+
 func main() {
-	user, err := user.Current()
-	if err != nil {
-		panic(err)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		greet(stdout)
+		repl.Start(stdin, stdout)
+		return exitOK
+	}
+
+	switch args[0] {
+	case "run":
+		return runScript(args[1:], stdin, stdout, stderr)
+	case "tokens":
+		return runTokens(args[1:], stdin, stdout, stderr)
+	case "parse":
+		return runParse(args[1:], stdin, stdout, stderr)
+	case "diff":
+		return runDiff(args[1:], stdin, stdout, stderr)
+	case "serve":
+		return runServe(args[1:], stdout, stderr)
+	case "lsp":
+		return runLSP(stdin, stdout, stderr)
+	case "highlight":
+		return runHighlight(args[1:], stdin, stdout, stderr)
+	case "lint":
+		return runLint(args[1:], stdin, stdout, stderr)
+	case "explain":
+		return runExplain(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprintln(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n%s\n", args[0], usage)
+		return exitUsage
+	}
+}
+
+func greet(out io.Writer) {
+	// コンテナなどではユーザーを取得できないことがあるので、そのときは名前を省く
+	if u, err := user.Current(); err == nil {
+		fmt.Fprintf(out, "Hello %s! This is the go-interpreter programming language!\n", u.Username)
+	} else {
+		fmt.Fprintln(out, "Hello! This is the go-interpreter programming language!")
 	}
-	fmt.Printf("Hello %s! This is the go-interpreter programming language!\n", user.Username)
-	fmt.Println("Feel free to type in commands")
-	repl.Start(os.Stdin, os.Stdout)
+	fmt.Fprintln(out, "Feel free to type in commands")
 }
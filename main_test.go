@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	// 構文エラーのメッセージを英語にする
+	t.Setenv("LC_ALL", "C")
+
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	ok := write("ok.mk", "#!/usr/bin/env go-interpreter run\nlet x = arg1 * argc;\nreturn x;\n")
+	syntax := write("syntax.mk", "let x = 1;\nlet y 2;\n")
+	runtime := write("runtime.mk", "let x = 1;\nx + true;\n")
+
+	cases := []struct {
+		name           string
+		args           []string
+		stdin          string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{"run", []string{"run", ok, "21", "2"}, "", exitOK, "", ""},
+		{"run stdin", []string{"run", "-"}, "1 + 2;", exitOK, "", ""},
+		{"syntax error", []string{"run", syntax}, "", exitError, "",
+			"error[E0001]: expected next token to be \"=\", got \"INT\" instead\n" +
+				" --> " + syntax + ":2:7\n" +
+				"  |\n" +
+				"2 | let y 2;\n" +
+				"  |       ^\n" +
+				"  = help: a let statement has the form 'let y = <expression>;'\n"},
+		{"runtime error", []string{"run", runtime}, "", exitError, "",
+			runtime + ":2:3: type mismatch: INTEGER + BOOLEAN\n"},
+		{"undefined name", []string{"run", "-"}, "retrun 5;", exitError, "",
+			"error: undefined: retrun\n" +
+				" --> <stdin>:1:1\n" +
+				"  |\n" +
+				"1 | retrun 5;\n" +
+				"  | ^^^^^^\n" +
+				"  = help: unknown identifier `retrun`; did you mean keyword `return`?\n"},
+		{"non-integer argument", []string{"run", ok, "foo"}, "", exitUsage, "",
+			"argument 1 (\"foo\") is not an integer\n"},
+		{"missing file", []string{"run", filepath.Join(dir, "missing.mk")}, "", exitUsage, "", ""},
+		{"tokens", []string{"tokens", "-"}, "x\n!= 1", exitOK,
+			"<stdin>:1:1\tIDENT\t\"x\"\n<stdin>:2:1\t!=\t\"!=\"\n<stdin>:2:4\tINT\t\"1\"\n", ""},
+		{"tokens illegal", []string{"tokens", "-"}, "x @", exitError,
+			"<stdin>:1:1\tIDENT\t\"x\"\n<stdin>:1:3\tILLEGAL\t\"@\"\n",
+			"error[E0004]: illegal character \"@\"\n" +
+				" --> <stdin>:1:3\n" +
+				"  |\n" +
+				"1 | x @\n" +
+				"  |   ^\n"},
+		{"parse", []string{"parse", "-"}, "let x = -a * b; x", exitOK,
+			"let x = ((-a) * b);\nx\n", ""},
+		{"highlight", []string{"highlight", "-format", "html", "-"}, "x;", exitOK,
+			`<pre class="highlight"><code><span class="identifier">x</span><span class="delimiter">;</span></code></pre>` + "\n", ""},
+		{"lint", []string{"lint", "-"}, "let x = 1;\n!!argc;", exitError,
+			"<stdin>:1:5: warning: x is declared but never used (unused)\n" +
+				"<stdin>:2:1: warning: redundant double negation of argc (double-negation)\n", ""},
+		{"lint undefined", []string{"lint", "-"}, "retrun 5;", exitError,
+			"<stdin>:1:1: error: undefined: retrun (undefined)\n" +
+				"\thelp: unknown identifier `retrun`; did you mean keyword `return`?\n", ""},
+		{"lint clean", []string{"lint", "-"}, "argc;", exitOK, "", ""},
+		{"lint fix", []string{"lint", "-fix", "-"}, "let x = 1;\nargc == argc;", exitOK, "\ntrue;", ""},
+		{"lint json", []string{"lint", "-format", "json", "-"}, "argc;", exitOK, "[]\n", ""},
+		{"diff", []string{"diff", ok, "-"}, "let x = arg1 * argc;\nreturn x;\n", exitOK, "", ""},
+		{"diff stdin twice", []string{"diff", "-", "-"}, "let x = 1;", exitUsage, "",
+			"diff: only one of <old> and <new> can be '-'\n"},
+		{"explain", []string{"explain", "e0001"}, "", exitOK,
+			"E0001: unexpected token\n\n" +
+				"The parser expected a specific token, such as '=' after the name in a let\n" +
+				"statement or ')' at the end of a grouped expression, but found another one.\n\n" +
+				"A let statement always has the form 'let <name> = <expression>;'. Check for a\n" +
+				"missing '=' or an unbalanced parenthesis near the reported position.\n\n" +
+				"Erroneous example:\n\n    let x 5;\n\n" +
+				"Corrected example:\n\n    let x = 5;\n", ""},
+		{"explain unknown", []string{"explain", "E9999"}, "", exitUsage, "",
+			"unknown error code \"E9999\", available: E0001, E0002, E0003, E0004, E0005\n"},
+		{"unknown command", []string{"foo"}, "", exitUsage, "", ""},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(c.args, strings.NewReader(c.stdin), &stdout, &stderr)
+			if code != c.expectedCode {
+				t.Fatalf("want exit code %d, got %d, stderr %q", c.expectedCode, code, stderr.String())
+			}
+			if stdout.String() != c.expectedStdout {
+				t.Fatalf("want stdout %q, got %q", c.expectedStdout, stdout.String())
+			}
+			if c.expectedStderr != "" && stderr.String() != c.expectedStderr {
+				t.Fatalf("want stderr %q, got %q", c.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
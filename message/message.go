@@ -0,0 +1,128 @@
+// message は構文エラーや字句解析のエラーのコードと、言語ごとのメッセージを保持する
+// parser と lexer が同じコードとメッセージを使うためのパッケージ
+package message
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hiroygo/go-interpreter/suggest"
+)
+
+// Code はエラーの種類を表す
+// メッセージの言語や文面が変わっても同じ値のままにする
+type Code string
+
+const (
+	// 次のトークンが期待したものではない
+	// e.g. 'let x 5;'
+	ErrUnexpectedToken Code = "E0001"
+	// 整数リテラルが int64 に収まらない
+	ErrInvalidInteger Code = "E0002"
+	// トークンから始まる式が無い
+	// e.g. ')'
+	ErrNoPrefixParseFn Code = "E0003"
+	// 字句解析できない文字がある
+	// e.g. '@'
+	ErrIllegalCharacter Code = "E0004"
+	// ParseExpr で式の後にトークンが残っている
+	// e.g. 'a + 1 b'
+	ErrTrailingToken Code = "E0005"
+)
+
+// エラー以外のメッセージのコード
+const (
+	HelpLetForm    Code = "H0001"
+	HintKeyword    Code = "H0002"
+	HintIdentifier Code = "H0003"
+)
+
+// Language はメッセージの言語を表す
+type Language string
+
+const (
+	English  Language = "en"
+	Japanese Language = "ja"
+)
+
+// catalog はコードと言語ごとのメッセージの書式を保持する
+// すべてのコードに、すべての言語のメッセージが必要
+var catalog = map[Code]map[Language]string{
+	ErrUnexpectedToken: {
+		English:  "expected next token to be %q, got %q instead",
+		Japanese: "次のトークンは %q のはずですが、%q でした",
+	},
+	ErrInvalidInteger: {
+		English:  "could not parse %q as integer",
+		Japanese: "%q を整数として解析できません",
+	},
+	ErrNoPrefixParseFn: {
+		English:  "no prefix parse function for %s found",
+		Japanese: "%s から始まる式は解析できません",
+	},
+	ErrIllegalCharacter: {
+		English:  "illegal character %q",
+		Japanese: "%q は使えない文字です",
+	},
+	ErrTrailingToken: {
+		English:  "unexpected %q after the expression",
+		Japanese: "式の後に %q があります",
+	},
+	HelpLetForm: {
+		English:  "a let statement has the form 'let %s = <expression>;'",
+		Japanese: "let 文は 'let %s = <式>;' の形式で書きます",
+	},
+	HintKeyword: {
+		English:  "unknown identifier `%s`; did you mean keyword `%s`?",
+		Japanese: "不明な識別子 `%s` です。キーワード `%s` の誤りではありませんか?",
+	},
+	HintIdentifier: {
+		English:  "unknown identifier `%s`; did you mean identifier `%s`?",
+		Japanese: "不明な識別子 `%s` です。識別子 `%s` の誤りではありませんか?",
+	},
+}
+
+// Has は code のメッセージがあるときに true を返す
+func Has(code Code) bool {
+	_, ok := catalog[code]
+	return ok
+}
+
+// Format は code の書式を lang で返す
+// lang のメッセージが無いときは英語にする
+func Format(lang Language, code Code, args ...interface{}) string {
+	format, ok := catalog[code][lang]
+	if !ok {
+		format = catalog[code][English]
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// HintMessage は h を lang のメッセージにして返す
+func HintMessage(lang Language, h suggest.Hint) string {
+	if h.Keyword {
+		return Format(lang, HintKeyword, h.Name, h.Suggestion)
+	}
+	return Format(lang, HintIdentifier, h.Name, h.Suggestion)
+}
+
+// LanguageFromEnv は環境変数からメッセージの言語を決める
+// POSIX のロケールと同じく LC_ALL, LC_MESSAGES, LANG の順に探し、
+// 最初に空ではなかった変数を使う
+// e.g. 'ja_JP.UTF-8' は Japanese
+func LanguageFromEnv() Language {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return parseLocale(v)
+		}
+	}
+	return English
+}
+
+func parseLocale(locale string) Language {
+	if strings.HasPrefix(locale, string(Japanese)) {
+		return Japanese
+	}
+	return English
+}
@@ -0,0 +1,57 @@
+package message
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/suggest"
+)
+
+func TestCatalog(t *testing.T) {
+	for code, msgs := range catalog {
+		en, ok := msgs[English]
+		if !ok || en == "" {
+			t.Errorf("%s: want an English message", code)
+		}
+		ja, ok := msgs[Japanese]
+		if !ok || ja == "" {
+			t.Errorf("%s: want a Japanese message", code)
+		}
+		// 同じ引数で書式を埋められるように、書式の数をそろえる
+		if strings.Count(en, "%") != strings.Count(ja, "%") {
+			t.Errorf("%s: want the same number of verbs in %q and %q", code, en, ja)
+		}
+	}
+}
+
+func TestHintMessage(t *testing.T) {
+	h := suggest.Hint{Name: "retrun", Suggestion: "return", Keyword: true}
+	if actual := HintMessage(English, h); actual != h.String() {
+		t.Fatalf("want HintMessage(English) = %q, got %q", h.String(), actual)
+	}
+	expected := "不明な識別子 `retrun` です。キーワード `return` の誤りではありませんか?"
+	if actual := HintMessage(Japanese, h); actual != expected {
+		t.Fatalf("want HintMessage(Japanese) = %q, got %q", expected, actual)
+	}
+}
+
+func TestLanguageFromEnv(t *testing.T) {
+	cases := []struct {
+		lcAll, lcMessages, lang string
+		expected                Language
+	}{
+		{"", "", "", English},
+		{"", "", "ja_JP.UTF-8", Japanese},
+		{"", "en_US.UTF-8", "ja_JP.UTF-8", English},
+		{"ja", "en_US.UTF-8", "", Japanese},
+		{"", "", "C", English},
+	}
+	for _, c := range cases {
+		t.Setenv("LC_ALL", c.lcAll)
+		t.Setenv("LC_MESSAGES", c.lcMessages)
+		t.Setenv("LANG", c.lang)
+		if actual := LanguageFromEnv(); actual != c.expected {
+			t.Errorf("LC_ALL=%q LC_MESSAGES=%q LANG=%q: want %s, got %s", c.lcAll, c.lcMessages, c.lang, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package object
+
+import "sort"
+
+// Environment は let で束縛した名前と値を保持する
+type Environment struct {
+	store map[string]Object
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{store: make(map[string]Object)}
+}
+
+func (e *Environment) Get(name string) (Object, bool) {
+	o, ok := e.store[name]
+	return o, ok
+}
+
+func (e *Environment) Set(name string, o Object) Object {
+	e.store[name] = o
+	return o
+}
+
+// Names は束縛されている名前を順に返す
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for n := range e.store {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
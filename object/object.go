@@ -0,0 +1,91 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// デバッグしやすいように string にしておく
+type ObjectType string
+
+const (
+	INTEGER_OBJ      = "INTEGER"
+	BOOLEAN_OBJ      = "BOOLEAN"
+	NULL_OBJ         = "NULL"
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	ERROR_OBJ        = "ERROR"
+)
+
+// 評価した値はすべて Object で表す
+type Object interface {
+	Type() ObjectType
+	// Inspect は REPL で値を表示するために使用する
+	Inspect() string
+}
+
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType {
+	return INTEGER_OBJ
+}
+
+func (i *Integer) Inspect() string {
+	return fmt.Sprintf("%d", i.Value)
+}
+
+// Boolean と Null は evaluator で 1 つのインスタンスを使い回す
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType {
+	return BOOLEAN_OBJ
+}
+
+func (b *Boolean) Inspect() string {
+	return fmt.Sprintf("%t", b.Value)
+}
+
+// Null は値が無いことを表す
+type Null struct{}
+
+func (n *Null) Type() ObjectType {
+	return NULL_OBJ
+}
+
+func (n *Null) Inspect() string {
+	return "null"
+}
+
+// ReturnValue は return 文の値を包んだもの
+// 包んだまま返すことで、外側の文の評価を打ち切れる
+type ReturnValue struct {
+	Value Object
+}
+
+func (r *ReturnValue) Type() ObjectType {
+	return RETURN_VALUE_OBJ
+}
+
+func (r *ReturnValue) Inspect() string {
+	return r.Value.Inspect()
+}
+
+// Error は実行時のエラーを表す
+// ReturnValue と同じように、評価を打ち切って外側に伝わる
+type Error struct {
+	Message string
+	// エラーが起きた式の位置
+	Pos token.Pos
+}
+
+func (e *Error) Type() ObjectType {
+	return ERROR_OBJ
+}
+
+func (e *Error) Inspect() string {
+	return "ERROR: " + e.Message
+}
@@ -0,0 +1,125 @@
+package parser
+
+import "github.com/hiroygo/go-interpreter/ast"
+
+// Arena は AST のノードをまとめて確保する
+// ノードを 1 つずつ確保する代わりに、種類ごとの配列から切り出すので確保の回数が減る
+// 1 つの Arena を複数の Parser で同時に使うことはできない
+type Arena struct {
+	identifiers []ast.Identifier
+	integers    []ast.IntegerLiteral
+	booleans    []ast.Boolean
+	prefixes    []ast.PrefixExpression
+	infixes     []ast.InfixExpression
+	groups      []ast.GroupedExpression
+	lets        []ast.LetStatement
+	returns     []ast.ReturnStatement
+	exprStmts   []ast.ExpressionStatement
+}
+
+func NewArena() *Arena {
+	return &Arena{}
+}
+
+// WithArena は AST のノードを a から確保する
+// 指定しないときはノードごとに確保する
+func WithArena(a *Arena) Option {
+	return func(p *Parser) {
+		p.arena = a
+	}
+}
+
+// Reset は確保したノードの領域を使い回せるようにする
+// Reset の前に構文解析した AST は、次の構文解析で書き換えられるので使えなくなる
+func (a *Arena) Reset() {
+	a.identifiers = a.identifiers[:0]
+	a.integers = a.integers[:0]
+	a.booleans = a.booleans[:0]
+	a.prefixes = a.prefixes[:0]
+	a.infixes = a.infixes[:0]
+	a.groups = a.groups[:0]
+	a.lets = a.lets[:0]
+	a.returns = a.returns[:0]
+	a.exprStmts = a.exprStmts[:0]
+}
+
+// 一度に確保するノードの最小の数
+const arenaChunk = 64
+
+// alloc は buf からゼロ値のノードを 1 つ切り出す
+// 足りないときは新しい配列を確保するので、以前に切り出したノードは動かない
+func alloc[T any](buf *[]T) *T {
+	if len(*buf) == cap(*buf) {
+		*buf = make([]T, 0, max(arenaChunk, 2*cap(*buf)))
+	}
+	*buf = (*buf)[:len(*buf)+1]
+	n := &(*buf)[len(*buf)-1]
+	var zero T
+	*n = zero
+	return n
+}
+
+// 以下のメソッドは a が nil のときはノードを 1 つずつ確保する
+
+func (a *Arena) identifier() *ast.Identifier {
+	if a == nil {
+		return &ast.Identifier{}
+	}
+	return alloc(&a.identifiers)
+}
+
+func (a *Arena) integer() *ast.IntegerLiteral {
+	if a == nil {
+		return &ast.IntegerLiteral{}
+	}
+	return alloc(&a.integers)
+}
+
+func (a *Arena) boolean() *ast.Boolean {
+	if a == nil {
+		return &ast.Boolean{}
+	}
+	return alloc(&a.booleans)
+}
+
+func (a *Arena) prefix() *ast.PrefixExpression {
+	if a == nil {
+		return &ast.PrefixExpression{}
+	}
+	return alloc(&a.prefixes)
+}
+
+func (a *Arena) infix() *ast.InfixExpression {
+	if a == nil {
+		return &ast.InfixExpression{}
+	}
+	return alloc(&a.infixes)
+}
+
+func (a *Arena) group() *ast.GroupedExpression {
+	if a == nil {
+		return &ast.GroupedExpression{}
+	}
+	return alloc(&a.groups)
+}
+
+func (a *Arena) let() *ast.LetStatement {
+	if a == nil {
+		return &ast.LetStatement{}
+	}
+	return alloc(&a.lets)
+}
+
+func (a *Arena) ret() *ast.ReturnStatement {
+	if a == nil {
+		return &ast.ReturnStatement{}
+	}
+	return alloc(&a.returns)
+}
+
+func (a *Arena) exprStmt() *ast.ExpressionStatement {
+	if a == nil {
+		return &ast.ExpressionStatement{}
+	}
+	return alloc(&a.exprStmts)
+}
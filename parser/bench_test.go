@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/lexer"
+)
+
+// ルールのサービスで評価するような小さな式
+var benchExprs = []string{
+	"a * 2 + b > 10",
+	"!(x == y) != true",
+	"-count * (total - 1) / 3 < limit",
+	"value",
+	"(a + b) * (c + d) == e",
+}
+
+// 式ごとに Lexer と Parser を作る
+func BenchmarkParseExprNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseExpr(benchExprs[i%len(benchExprs)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Lexer と Parser を Reset で使い回す
+func BenchmarkParseExprReset(b *testing.B) {
+	l := lexer.New("")
+	p := New(l)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Reset(benchExprs[i%len(benchExprs)])
+		p.Reset(l)
+		if _, err := p.ParseExpr(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// さらにノードを Arena から確保する
+func BenchmarkParseExprArena(b *testing.B) {
+	a := NewArena()
+	l := lexer.New("")
+	p := New(l, WithArena(a))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		a.Reset()
+		l.Reset(benchExprs[i%len(benchExprs)])
+		p.Reset(l)
+		if _, err := p.ParseExpr(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hiroygo/go-interpreter/message"
+	"github.com/hiroygo/go-interpreter/suggest"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Error は位置付きの構文エラーを表す
+type Error struct {
+	// エラーの原因になったトークンの範囲
+	Pos token.Pos
+	End token.Pos
+	// エラーの種類
+	Code Code
+	// Lang で書かれたメッセージ
+	Msg string
+	// Msg や Help の言語
+	Lang Language
+	// 補足の説明
+	Notes []string
+	// 直し方の提案
+	Help []string
+	// 綴りを誤ったと思われる名前の候補
+	Hints []suggest.Hint
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// ErrorList は構文エラーを見つけた順に保持する
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err は l が空のときに nil を返し、それ以外は l を error として返す
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+func (p *Parser) errorf(t token.Token, code Code, args ...interface{}) {
+	p.errors = append(p.errors, &Error{
+		Pos:  t.Pos,
+		End:  t.End(),
+		Code: code,
+		Msg:  message.Format(p.lang, code, args...),
+		Lang: p.lang,
+	})
+}
+
+// help は最後に記録した構文エラーに直し方の提案を付ける
+func (p *Parser) help(code Code, args ...interface{}) {
+	if len(p.errors) == 0 {
+		return
+	}
+	e := p.errors[len(p.errors)-1]
+	e.Help = append(e.Help, message.Format(p.lang, code, args...))
+}
+
+// hintKeyword は t がキーワードの綴りを誤ったものに見えるとき、
+// first 以降に記録した最初の構文エラーに候補を付ける
+// 綴りだけでエラーにはせず、構文エラーがあるときにだけ候補を出す
+// e.g. 'lett x = 5;' の 'lett'
+func (p *Parser) hintKeyword(t token.Token, first int) {
+	if t.Type != token.IDENT || len(p.errors) <= first {
+		return
+	}
+	kw, ok := suggest.Closest(t.Literal, token.Keywords())
+	if !ok {
+		return
+	}
+	e := p.errors[first]
+	h := suggest.Hint{Name: t.Literal, Suggestion: kw, Keyword: true}
+	if slices.Contains(e.Hints, h) {
+		return
+	}
+	e.Hints = append(e.Hints, h)
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// Explanation はエラーコードの詳しい説明を表す
+type Explanation struct {
+	Code Code
+	// 1 行の要約
+	Title string
+	// 原因と直し方の説明
+	Text string
+	// このコードのエラーになるプログラム
+	Erroneous string
+	// Erroneous を直したプログラム
+	Corrected string
+	// 例がプログラムではなく ParseExpr で解析する式のとき true
+	Expr bool
+}
+
+// explanations の例はテストで実際に構文解析して確かめる
+// Erroneous は最初のエラーが Code になり、Corrected はエラーにならない
+var explanations = map[Code]Explanation{
+	ErrUnexpectedToken: {
+		Title: "unexpected token",
+		Text: `The parser expected a specific token, such as '=' after the name in a let
+statement or ')' at the end of a grouped expression, but found another one.
+
+A let statement always has the form 'let <name> = <expression>;'. Check for a
+missing '=' or an unbalanced parenthesis near the reported position.`,
+		Erroneous: "let x 5;",
+		Corrected: "let x = 5;",
+	},
+	ErrInvalidInteger: {
+		Title: "integer literal out of range",
+		Text: `Integers are 64-bit signed values, so an integer literal must be at most
+9223372036854775807. Larger values cannot be written as literals.`,
+		Erroneous: "let big = 9223372036854775808;",
+		Corrected: "let big = 9223372036854775807;",
+	},
+	ErrNoPrefixParseFn: {
+		Title: "expected an expression",
+		Text: `An expression was expected, but the token at the reported position cannot
+start one. This usually means an operand is missing, as in 'let x = * 2;', or
+that a closing delimiter such as ')' or ';' appears where a value should be.
+
+Expressions start with a name, an integer, 'true' or 'false', a prefix operator
+('!' or '-') or an opening parenthesis.`,
+		Erroneous: "let x = * 2;",
+		Corrected: "let x = 1 * 2;",
+	},
+	ErrIllegalCharacter: {
+		Title: "illegal character",
+		Text: `The source contains a character that is not part of the language. Names may
+only use ASCII letters, digits and '_', and only the operators and delimiters
+listed in the token package are recognized.`,
+		Erroneous: "let total = 1 @ 2;",
+		Corrected: "let total = 1 + 2;",
+	},
+	ErrTrailingToken: {
+		Title: "trailing tokens after an expression",
+		Text: `ParseExpr parses exactly one expression, such as a formula embedded in a
+configuration file, and fails if anything follows it. A semicolon or a second
+expression is not allowed; split them or use a full program instead.`,
+		Erroneous: "price * 2;",
+		Corrected: "price * 2",
+		Expr:      true,
+	},
+}
+
+// Codes はすべてのエラーコードを順に返す
+func Codes() []Code {
+	var cs []Code
+	for c := range explanations {
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i] < cs[j]
+	})
+	return cs
+}
+
+// Explain は code の説明を返す
+// 小文字のコードも受け付ける
+// e.g. 'e0003'
+func Explain(code string) (Explanation, bool) {
+	c := Code(strings.ToUpper(code))
+	e, ok := explanations[c]
+	e.Code = c
+	return e, ok
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/message"
+)
+
+func TestExplanations(t *testing.T) {
+	for _, code := range Codes() {
+		e, ok := Explain(string(code))
+		if !ok || e.Code != code {
+			t.Fatalf("Explain(%s): want an explanation", code)
+		}
+		if e.Title == "" || e.Text == "" {
+			t.Errorf("%s: want a title and a text", code)
+		}
+		if !message.Has(code) {
+			t.Errorf("%s: want a message in the catalog", code)
+		}
+
+		if errs := parseExample(e.Erroneous, e.Expr); len(errs) == 0 || errs[0].Code != code {
+			t.Errorf("%s: want the erroneous example %q to fail with %s, got %v", code, e.Erroneous, code, errs)
+		}
+		if errs := parseExample(e.Corrected, e.Expr); len(errs) != 0 {
+			t.Errorf("%s: want the corrected example %q to parse, got %v", code, e.Corrected, errs)
+		}
+	}
+}
+
+func parseExample(src string, expr bool) ErrorList {
+	if expr {
+		_, err := ParseExpr(src)
+		errs, _ := err.(ErrorList)
+		return errs
+	}
+	p := New(lexer.New(src))
+	p.ParseProgram()
+	return p.ErrorList()
+}
+
+func TestExplainUnknown(t *testing.T) {
+	if _, ok := Explain("E9999"); ok {
+		t.Fatal("want Explain(E9999) to fail")
+	}
+	if e, ok := Explain("e0003"); !ok || e.Code != ErrNoPrefixParseFn {
+		t.Fatalf("want Explain(e0003) = %s, got %s", ErrNoPrefixParseFn, e.Code)
+	}
+}
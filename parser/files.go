@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Ext はスクリプトのファイルの拡張子
+// ParseDir はこの拡張子のファイルだけを構文解析する
+const Ext = ".mk"
+
+// ParsedFile は 1 つのファイルを構文解析した結果
+type ParsedFile struct {
+	Path string
+	// 読み込めなかったときは nil
+	File    *token.File
+	Program *ast.Program
+	// 構文エラー
+	Errors ErrorList
+	// ファイルを読み込めなかったときのエラー
+	Err error
+}
+
+// ErrNotConcurrent は並行に使えない設定を ParseFiles に渡したときのエラー
+var ErrNotConcurrent = errors.New("WithTracer, WithTrace and WithArena cannot be used with ParseFiles")
+
+// WithFileRegistry は ParseFiles と ParseDir で読み込んだファイルを r に登録する
+// 複数回の呼び出しで r を共有すると、すべてのファイルの File を名前で引ける
+// 指定しないときはどこにも登録しない
+func WithFileRegistry(r *token.FileRegistry) Option {
+	return func(p *Parser) {
+		p.files = r
+	}
+}
+
+// ParseFiles は paths のファイルを並行に構文解析し、paths と同じ順番で結果を返す
+// 同時に構文解析するファイルの数は GOMAXPROCS までにする
+//
+// token.Pos はファイルごとの位置なので、別のファイルの Pos を比べても意味は無い
+// 位置を表示するときは、そのノードを含む ParsedFile の File を使う
+//
+// 読み込めないファイルや構文エラーは結果の ParsedFile に入れて、ほかのファイルの構文解析を続ける
+// ctx が終わったときは構文解析を止めて ctx のエラーを返す
+// opts は各ファイルの Parser に使い、Lexer の設定は WithLexerOptions で渡す
+// WithTracer や WithArena のように並行に使えない設定を渡したときは ErrNotConcurrent を返す
+func ParseFiles(ctx context.Context, paths []string, opts ...Option) ([]*ParsedFile, error) {
+	var conf Parser
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	if conf.tracer != nil || conf.arena != nil {
+		return nil, ErrNotConcurrent
+	}
+
+	files := make([]*ParsedFile, len(paths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := min(runtime.GOMAXPROCS(0), len(paths)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Lexer と Parser は Reset で使い回す
+			var (
+				l *lexer.Lexer
+				p *Parser
+			)
+			for i := range jobs {
+				f := &ParsedFile{Path: paths[i]}
+				files[i] = f
+				b, err := os.ReadFile(f.Path)
+				if err != nil {
+					f.Err = err
+					continue
+				}
+				src := string(b)
+				if conf.files != nil {
+					f.File = conf.files.AddFile(f.Path, src)
+				} else {
+					f.File = token.NewFile(f.Path, src)
+				}
+				if p == nil {
+					l = lexer.New(src, conf.lexOpts...)
+					p = New(l, opts...)
+				} else {
+					l.Reset(src)
+					p.Reset(l)
+				}
+				f.Program = p.ParseProgram()
+				f.Errors = p.ErrorList()
+			}
+		}()
+	}
+
+	// ctx が終わったら残りのファイルは渡さない
+send:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// ParseDir は dir の直下にある拡張子が Ext のファイルを、名前の順に ParseFiles で構文解析する
+func ParseDir(ctx context.Context, dir string, opts ...Option) ([]*ParsedFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), Ext) {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	return ParseFiles(ctx, paths, opts...)
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParseFiles(t *testing.T) {
+	files := map[string]string{}
+	var paths []string
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("f%02d.mk", i)
+		files[name] = fmt.Sprintf("let x%d = %d;\nx%d * 2;\n", i, i, i)
+		if i%10 == 0 {
+			files[name] += "let y 1;\n"
+		}
+	}
+	dir := writeFiles(t, files)
+	// 名前の順とは逆に並べても、結果は paths の順になる
+	for i := 49; i >= 0; i-- {
+		paths = append(paths, filepath.Join(dir, fmt.Sprintf("f%02d.mk", i)))
+	}
+	paths = append(paths, filepath.Join(dir, "missing.mk"))
+
+	reg := token.NewFileRegistry()
+	got, err := ParseFiles(context.Background(), paths, WithFileRegistry(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(paths) {
+		t.Fatalf("want %d results, got %d", len(paths), len(got))
+	}
+	for i, f := range got[:50] {
+		n := 49 - i
+		if f.Path != paths[i] || f.Err != nil {
+			t.Fatalf("%d: want %s to be read, got %s (%v)", i, paths[i], f.Path, f.Err)
+		}
+		if want := fmt.Sprintf("let x%d = %d;(x%d * 2)", n, n, n); !strings.HasPrefix(f.Program.String(), want) {
+			t.Errorf("%s: want %q, got %q", f.Path, want, f.Program.String())
+		}
+		if wantErr := n%10 == 0; (len(f.Errors) != 0) != wantErr {
+			t.Errorf("%s: want errors = %t, got %v", f.Path, wantErr, f.Errors)
+		}
+		if reg.File(f.Path) != f.File {
+			t.Errorf("%s: want the file in the registry", f.Path)
+		}
+	}
+	if missing := got[50]; !errors.Is(missing.Err, os.ErrNotExist) || missing.Program != nil {
+		t.Errorf("want an error for the missing file, got %v", missing.Err)
+	}
+	if len(reg.Files()) != 50 {
+		t.Errorf("want 50 files in the registry, got %d", len(reg.Files()))
+	}
+}
+
+func TestParseFilesCancel(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.mk": "let a = 1;"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	paths := make([]string, 100)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, "a.mk")
+	}
+	if _, err := ParseFiles(ctx, paths); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+}
+
+func TestParseDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"b.mk":      "let b = 2;",
+		"a.mk":      "let a = 1;",
+		"notes.txt": "let",
+	})
+	if err := os.Mkdir(filepath.Join(dir, "sub.mk"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseDir(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || filepath.Base(got[0].Path) != "a.mk" || filepath.Base(got[1].Path) != "b.mk" {
+		t.Fatalf("want a.mk and b.mk in order, got %v", got)
+	}
+	if _, err := ParseDir(context.Background(), filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("want an error for a missing directory")
+	}
+}
+
+func TestParseFilesOptions(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.mk": "変数 a = 真;",
+		"b.mk": "変数 b = 偽;",
+	})
+	paths := []string{filepath.Join(dir, "a.mk"), filepath.Join(dir, "b.mk")}
+
+	// FileRegistry を渡さなくても File は作る
+	in := token.NewInterner()
+	got, err := ParseFiles(context.Background(), paths,
+		WithLexerOptions(lexer.WithDialect(token.Japanese), lexer.WithInterner(in)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range got {
+		if f.File == nil || len(f.Errors) != 0 || !strings.HasPrefix(f.Program.String(), "変数 ") {
+			t.Errorf("%s: want a Japanese program, got %q %v", f.Path, f.Program, f.Errors)
+		}
+	}
+	if in.Len() != 2 {
+		t.Errorf("want the shared Interner to hold a and b, got %d names", in.Len())
+	}
+
+	// 並行に使えない設定は受け付けない
+	for _, opt := range []Option{WithTracer(func(TraceEvent) {}), WithArena(NewArena())} {
+		if _, err := ParseFiles(context.Background(), paths, opt); !errors.Is(err, ErrNotConcurrent) {
+			t.Errorf("want ErrNotConcurrent, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/internal/golden"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// testdata の構文解析の結果を '<name>.ast' と、構文エラーを '<name>.errors' と比べる
+// サブテストは 'TestGolden/ast/let' のように比べるファイルの種類ごとに分ける
+func TestGolden(t *testing.T) {
+	t.Run("ast", func(t *testing.T) {
+		golden.Run(t, "../testdata", "ast", func(t *testing.T, src string) string {
+			p := New(lexer.New(src))
+			return dump(token.NewFile("", src), p.ParseProgram())
+		})
+	})
+	t.Run("errors", func(t *testing.T) {
+		golden.Run(t, "../testdata", "errors", func(t *testing.T, src string) string {
+			p := New(lexer.New(src))
+			p.ParseProgram()
+			file := token.NewFile("", src)
+			var b strings.Builder
+			for _, e := range p.ErrorList() {
+				fmt.Fprintf(&b, "%s: %s: %s\n", file.Position(e.Pos), e.Code, e.Msg)
+				for _, h := range e.Hints {
+					fmt.Fprintf(&b, "\thint: %s\n", h)
+				}
+			}
+			return b.String()
+		})
+	})
+}
+
+// dump はノードを 1 行ずつ、深さだけ字下げして範囲と一緒に書き出す
+func dump(file *token.File, prg *ast.Program) string {
+	var b strings.Builder
+	depth := 0
+	ast.Inspect(prg, func(n ast.Node) bool {
+		if n == nil {
+			depth--
+			return false
+		}
+		fmt.Fprintf(&b, "%s%T %s-%s", strings.Repeat("  ", depth), n, file.Position(n.Pos()), file.Position(n.End()))
+		switch n := n.(type) {
+		case *ast.Identifier:
+			fmt.Fprintf(&b, " %s", n.Value)
+		case *ast.IntegerLiteral:
+			fmt.Fprintf(&b, " %d", n.Value)
+		case *ast.Boolean:
+			fmt.Fprintf(&b, " %t", n.Value)
+		case *ast.PrefixExpression:
+			fmt.Fprintf(&b, " %s", n.Operator)
+		case *ast.InfixExpression:
+			fmt.Fprintf(&b, " %s", n.Operator)
+		}
+		b.WriteString("\n")
+		depth++
+		return true
+	})
+	return b.String()
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// Edit はソースの [Pos, End) を Text に置き換える書き換えを表す
+// e.g. エディタでの 1 回の入力
+type Edit struct {
+	Pos  token.Pos
+	End  token.Pos
+	Text string
+}
+
+// Apply は src に e を適用したソースを返す
+// e の範囲は src の中になければならない
+func (e Edit) Apply(src string) string {
+	return src[:e.Pos.Offset()] + e.Text + src[e.End.Offset():]
+}
+
+// delta は e の後ろの位置がずれる量を返す
+func (e Edit) delta() int {
+	return len(e.Text) - int(e.End-e.Pos)
+}
+
+// Reparse は old を構文解析した結果 prev と errs を使って、edit を適用したソースを構文解析する
+// 結果はソース全体を構文解析し直したときと同じになる
+// Lexer の設定は opts の WithLexerOptions で渡す
+//
+// 書き換えを含む文の先頭から字句解析と構文解析をやり直し、書き換えの後ろで前回と同じ文の先頭に
+// たどり着いたところでやめる
+// 書き換えの前の文はそのまま、後ろの文は位置をずらして再利用する
+// そのため prev の文は書き換えられるので、Reparse の後は prev を使わない
+func Reparse(prev *ast.Program, errs ErrorList, old string, edit Edit, opts ...Option) (*ast.Program, ErrorList) {
+	src := edit.Apply(old)
+	delta := edit.delta()
+	lexOpts := lexerOptions(opts)
+
+	// 前回のエラーと同じ位置から始まる文では、どちらの文の構文解析で出たエラーか区別できないので
+	// そこから構文解析を始めたり、そこで止めたりしない
+	errPos := map[token.Pos]bool{}
+	for _, e := range errs {
+		errPos[e.Pos] = true
+	}
+
+	// 最初のトークンが書き換えに触れていない最後の文から構文解析をやり直す
+	// 直前の文も、セミコロンが省略されていると書き換えた部分に続くことがある
+	// トークンの直後の文字もトークンの終わりを決めるので、書き換えの先頭とは離れている必要がある
+	// キーワードの候補は ';' で区切ったまとまりごとに付けるので、まとまりの先頭の文に限る
+	// そのような文が無いときは先頭からやり直す
+	k, restart := 0, token.NoPos
+	for i := len(prev.Statements) - 1; i >= 0; i-- {
+		s := prev.Statements[i]
+		if firstToken(s).End() < edit.Pos && !errPos[s.Pos()] && startsRun(old, prev, i, lexOpts) {
+			k, restart = i, s.Pos()
+			break
+		}
+	}
+
+	prg := &ast.Program{Statements: append([]ast.Statement{}, prev.Statements[:k]...)}
+	var list ErrorList
+	offset := 0
+	if restart.IsValid() {
+		offset = restart.Offset()
+		for _, e := range errs {
+			if e.Pos < restart {
+				list = append(list, e)
+			}
+		}
+	}
+
+	p := New(lexer.New(src, append([]lexer.Option{lexer.WithOffset(offset)}, lexOpts...)...), opts...)
+	j := k
+	for p.curToken.Type != token.EOF {
+		// 書き換えの後ろのトークンは前回の位置に戻して比べる
+		pos := p.curToken.Pos - token.Pos(delta)
+		for j < len(prev.Statements) && prev.Statements[j].Pos() < pos {
+			j++
+		}
+		if j < len(prev.Statements) && prev.Statements[j].Pos() == pos && pos >= edit.End && !errPos[pos] &&
+			!p.runOpen && startsRun(old, prev, j, lexOpts) {
+			// ここから後ろは前回と同じトークンが続くので、構文解析の結果も同じになる
+			list = append(list, p.errors...)
+			for _, s := range prev.Statements[j:] {
+				ast.Shift(s, delta)
+				prg.Statements = append(prg.Statements, s)
+			}
+			for _, e := range errs {
+				if e.Pos > pos {
+					list = append(list, shiftError(e, delta))
+				}
+			}
+			return prg, list
+		}
+
+		if s := p.parseTopLevelStatement(); s != nil {
+			prg.Statements = append(prg.Statements, s)
+		}
+		p.nextToken()
+	}
+	return prg, append(list, p.errors...)
+}
+
+// startsRun は src を構文解析した prg の i 番目の文が、';' で区切ったまとまりの先頭のときに true を返す
+// 直前の文の先頭から字句解析し直して、文の直前のトークンが ';' か、文より前にトークンが無いことを確かめる
+func startsRun(src string, prg *ast.Program, i int, lexOpts []lexer.Option) bool {
+	offset := 0
+	if i > 0 {
+		offset = prg.Statements[i-1].Pos().Offset()
+	}
+	pos := prg.Statements[i].Pos()
+	l := lexer.New(src, append([]lexer.Option{lexer.WithOffset(offset)}, lexOpts...)...)
+	last := token.Token{Type: token.SEMICOLON}
+	for t := l.NextToken(); t.Type != token.EOF && t.Pos < pos; t = l.NextToken() {
+		last = t
+	}
+	return last.Type == token.SEMICOLON
+}
+
+// firstToken は文の最初のトークンを返す
+func firstToken(s ast.Statement) token.Token {
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		return s.Token
+	case *ast.ReturnStatement:
+		return s.Token
+	case *ast.ExpressionStatement:
+		return s.Token
+	}
+	return token.Token{}
+}
+
+// shiftError は e の位置をずらした複製を返す
+// 前回の ErrorList は書き換えない
+func shiftError(e *Error, delta int) *Error {
+	c := *e
+	c.Pos += token.Pos(delta)
+	if c.End.IsValid() {
+		c.End += token.Pos(delta)
+	}
+	return &c
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+func parseAll(src string) (*ast.Program, ErrorList) {
+	p := New(lexer.New(src))
+	prg := p.ParseProgram()
+	return prg, p.ErrorList()
+}
+
+// dumpAll は位置も含めて AST とエラーを文字列にする
+func dumpAll(src string, prg *ast.Program, errs ErrorList) string {
+	var b strings.Builder
+	b.WriteString(dump(token.NewFile("", src), prg))
+	for _, e := range errs {
+		fmt.Fprintf(&b, "%+v\n", *e)
+	}
+	return b.String()
+}
+
+func TestReparseReuse(t *testing.T) {
+	old := "let a = 1;\nlet b = a + 2;\nlet c = b * 3;\nreturn c;"
+	prev, errs := parseAll(old)
+	first, last := prev.Statements[0], prev.Statements[3]
+
+	// 'a + 2' を 'a + 20' にする
+	at := token.PosFromOffset(strings.Index(old, "2;") + 1)
+	prg, list := Reparse(prev, errs, old, Edit{Pos: at, End: at, Text: "0"})
+	if len(list) != 0 {
+		t.Fatalf("want no errors, got %v", list)
+	}
+	if prg.Statements[0] != first || prg.Statements[3] != last {
+		t.Fatal("want the statements around the edit to be reused")
+	}
+	if got := prg.Statements[1].String(); got != "let b = (a + 20);" {
+		t.Fatalf("want the edited statement reparsed, got %q", got)
+	}
+	if last.Pos() != token.PosFromOffset(strings.Index(old, "return")+1) {
+		t.Fatalf("want the reused statement shifted, got %d", last.Pos())
+	}
+}
+
+// 書き換えに使う断片
+// 文の区切りやコメント、エラーになる断片を多めにする
+var editTexts = []string{
+	"", " ", "\n", "x", "1", "let ", "return ", ";", "=", "==", "!", "+", "*",
+	"(", ")", "{", "}", "//", "fn", "retrun", "let x = 2;", "a b", "関数",
+}
+
+var sourceParts = []string{
+	"let x = 1;", "let y = x + 2", "return y;", "x * (y - 3);", "!true == false;",
+	"let = 5;", "if (x) { 1 }", "retrun 5;", "// comment\n", "let z = (1 + ;",
+	"-a", ";", "y", "1 2", "#", "(x", "let 関数 = 1;",
+}
+
+func randomSource(r *rand.Rand) string {
+	var b strings.Builder
+	if r.Intn(10) == 0 {
+		b.WriteString("#!/usr/bin/env monkey\n")
+	}
+	for n := r.Intn(10); n > 0; n-- {
+		b.WriteString(sourceParts[r.Intn(len(sourceParts))])
+		b.WriteString([]string{"", " ", "\n"}[r.Intn(3)])
+	}
+	return b.String()
+}
+
+func randomEdit(r *rand.Rand, src string) Edit {
+	pos := r.Intn(len(src) + 1)
+	end := min(len(src), pos+r.Intn(8))
+	return Edit{
+		Pos:  token.PosFromOffset(pos),
+		End:  token.PosFromOffset(end),
+		Text: editTexts[r.Intn(len(editTexts))],
+	}
+}
+
+func TestReparseRandomEdits(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		src := randomSource(r)
+		prg, errs := parseAll(src)
+		// 前回の Reparse の結果に続けて書き換える
+		for j := 0; j < 20; j++ {
+			edit := randomEdit(r, src)
+			next := edit.Apply(src)
+			prg, errs = Reparse(prg, errs, src, edit)
+
+			full, fullErrs := parseAll(next)
+			want, got := dumpAll(next, full, fullErrs), dumpAll(next, prg, errs)
+			if got != want {
+				t.Fatalf("source %q, edit %+v:\nwant:\n%s\ngot:\n%s", src, edit, want, got)
+			}
+			src = next
+		}
+	}
+}
+
+func TestReparseDialect(t *testing.T) {
+	opts := []Option{WithLexerOptions(lexer.WithDialect(token.Japanese))}
+	parse := func(src string) (*ast.Program, ErrorList) {
+		p := New(lexer.New(src, lexer.WithDialect(token.Japanese)), opts...)
+		return p.ParseProgram(), p.ErrorList()
+	}
+
+	old := "変数 a = 1;\n変数 b = a + 2;\n返す b;"
+	prev, errs := parse(old)
+	at := token.PosFromOffset(strings.Index(old, "2;"))
+	edit := Edit{Pos: at, End: at + 1, Text: "真"}
+	prg, list := Reparse(prev, errs, old, edit, opts...)
+
+	full, fullErrs := parse(edit.Apply(old))
+	if len(list) != 0 || dumpAll(edit.Apply(old), prg, list) != dumpAll(edit.Apply(old), full, fullErrs) {
+		t.Fatalf("want the same result as a full reparse with the dialect, got %q %v", prg, list)
+	}
+	if got := prg.Statements[1].String(); got != "変数 b = (a + 真);" {
+		t.Fatalf("want the Japanese keyword in the edit, got %q", got)
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"github.com/hiroygo/go-interpreter/message"
+	"github.com/hiroygo/go-interpreter/suggest"
+)
+
+// Code は構文エラーの種類を表す
+// lexer と同じコードを使うため、コードとメッセージは message パッケージにある
+type Code = message.Code
+
+const (
+	ErrUnexpectedToken  = message.ErrUnexpectedToken
+	ErrInvalidInteger   = message.ErrInvalidInteger
+	ErrNoPrefixParseFn  = message.ErrNoPrefixParseFn
+	ErrIllegalCharacter = message.ErrIllegalCharacter
+	ErrTrailingToken    = message.ErrTrailingToken
+)
+
+// Language はメッセージの言語を表す
+type Language = message.Language
+
+const (
+	English  = message.English
+	Japanese = message.Japanese
+)
+
+// HintMessage は h を lang のメッセージにして返す
+func HintMessage(lang Language, h suggest.Hint) string {
+	return message.HintMessage(lang, h)
+}
+
+// LanguageFromEnv は環境変数からメッセージの言語を決める
+// e.g. 'ja_JP.UTF-8' は Japanese
+func LanguageFromEnv() Language {
+	return message.LanguageFromEnv()
+}
+
+// Option は New で Parser の設定を変える
+type Option func(*Parser)
+
+// WithLanguage はエラーメッセージの言語を lang にする
+// 指定しないときは English
+func WithLanguage(lang Language) Option {
+	return func(p *Parser) {
+		p.lang = lang
+	}
+}
@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/lexer"
+)
+
+func TestWithLanguage(t *testing.T) {
+	cases := []struct {
+		lang     Language
+		expected string
+		help     string
+	}{
+		{English, `expected next token to be "=", got "INT" instead`, "a let statement has the form 'let x = <expression>;'"},
+		{Japanese, `次のトークンは "=" のはずですが、"INT" でした`, "let 文は 'let x = <式>;' の形式で書きます"},
+	}
+
+	for _, c := range cases {
+		p := New(lexer.New("let x 5;"), WithLanguage(c.lang))
+		p.ParseProgram()
+		errs := p.ErrorList()
+		if len(errs) != 1 {
+			t.Fatalf("%s: want 1 error, got %v", c.lang, errs)
+		}
+		e := errs[0]
+		if e.Code != ErrUnexpectedToken || e.Lang != c.lang || e.Msg != c.expected {
+			t.Fatalf("%s: want (%s, %q), got (%s, %q)", c.lang, ErrUnexpectedToken, c.expected, e.Code, e.Msg)
+		}
+		if len(e.Help) != 1 || e.Help[0] != c.help {
+			t.Fatalf("%s: want help %q, got %q", c.lang, c.help, e.Help)
+		}
+	}
+}
@@ -1,11 +1,11 @@
 package parser
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/hiroygo/go-interpreter/ast"
 	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/message"
 	"github.com/hiroygo/go-interpreter/token"
 )
 
@@ -21,7 +21,9 @@ const (
 )
 
 // 演算子の優先順位
-var precedences = map[token.TokenType]int{
+// トークンの種類を添字にして引く
+// 0 のところは中置演算子ではない
+var precedences = [token.NumTypes]int{
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
 	token.LT:       LESSGREATER,
@@ -33,80 +35,151 @@ var precedences = map[token.TokenType]int{
 }
 
 type (
-	prefixParseFn func() ast.Expression
-	infixParseFn  func(ast.Expression) ast.Expression
+	prefixParseFn func(*Parser) ast.Expression
+	infixParseFn  func(*Parser, ast.Expression) ast.Expression
 )
 
+// TokenType と構文解析関数を対応させる
+// どの Parser でも同じなので、New のたびに作らずにトークンの種類を添字にした表にする
+// 構文解析関数から表を参照するので、初期化の循環を避けるために init で登録する
+var (
+	prefixParseFns [token.NumTypes]prefixParseFn
+	infixParseFns  [token.NumTypes]infixParseFn
+)
+
+func init() {
+	registerPrefix(token.IDENT, (*Parser).parseIdentifier)
+	registerPrefix(token.INT, (*Parser).parseIntegerLiteral)
+	registerPrefix(token.BANG, (*Parser).parsePrefixExpression)
+	registerPrefix(token.MINUS, (*Parser).parsePrefixExpression)
+	registerPrefix(token.TRUE, (*Parser).parseBoolean)
+	registerPrefix(token.FALSE, (*Parser).parseBoolean)
+	registerPrefix(token.LPAREN, (*Parser).parseGroupedExpression)
+
+	registerInfix(token.PLUS, (*Parser).parseInfixExpression)
+	registerInfix(token.MINUS, (*Parser).parseInfixExpression)
+	registerInfix(token.SLASH, (*Parser).parseInfixExpression)
+	registerInfix(token.ASTERISK, (*Parser).parseInfixExpression)
+	registerInfix(token.EQ, (*Parser).parseInfixExpression)
+	registerInfix(token.NOT_EQ, (*Parser).parseInfixExpression)
+	registerInfix(token.LT, (*Parser).parseInfixExpression)
+	registerInfix(token.GT, (*Parser).parseInfixExpression)
+}
+
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors ErrorList
+	// エラーメッセージの言語
+	lang Language
+	// 構文解析の関数の呼び出しを受け取る
+	// nil のときは記録しない
+	tracer     func(TraceEvent)
+	traceDepth int
+
+	// ';' の次から次の ';' までの文のまとまりの最初のトークン
+	// 'retrun 5;' のように、キーワードの綴りを誤ると 1 つの文が複数の文に分かれるので、
+	// まとまりの中のエラーに候補を付ける
+	run     token.Token
+	runErrs int
+	runOpen bool
+
+	// Parser が作る Lexer の設定
+	lexOpts []lexer.Option
+	// ParseFiles で読み込んだファイルを登録する
+	files *token.FileRegistry
+
+	// AST のノードを確保する
+	// nil のときはノードごとに確保する
+	arena *Arena
 
 	curToken  token.Token
 	peekToken token.Token
+}
+
+func New(l *lexer.Lexer, opts ...Option) *Parser {
+	p := &Parser{l: l, lang: English}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.Reset(l)
+	return p
+}
+
+// WithLexerOptions は ParseExpr や Reparse のように、Parser が Lexer を作るときに opts を渡す
+// New に渡した Lexer には使わない
+// e.g. 'WithLexerOptions(lexer.WithDialect(token.Japanese))'
+func WithLexerOptions(opts ...lexer.Option) Option {
+	return func(p *Parser) {
+		p.lexOpts = append(p.lexOpts, opts...)
+	}
+}
 
-	// TokenType と構文解析関数を対応させる
-	prefixParseFns map[token.TokenType]prefixParseFn
-	infixParseFns  map[token.TokenType]infixParseFn
+// lexerOptions は opts のうち WithLexerOptions で指定したものを返す
+func lexerOptions(opts []Option) []lexer.Option {
+	var p Parser
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return p.lexOpts
 }
 
-func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l}
+// Reset は設定をそのままにして、p で l の入力を新しく構文解析できるようにする
+// 小さな入力をたくさん構文解析するときに、Parser を作り直さずに使い回す
+// 以前の ErrorList は書き換えない
+func (p *Parser) Reset(l *lexer.Lexer) {
+	p.l = l
+	p.errors = nil
+	p.traceDepth = 0
+	p.runOpen = false
+	p.curToken = token.Token{}
+	p.peekToken = token.Token{}
 
 	// curToken と peekToken を初期位置にセットする
 	p.nextToken()
 	p.nextToken()
-
-	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
-	p.registerPrefix(token.IDENT, p.parseIdentifier)
-	p.registerPrefix(token.INT, p.parseIntegerLiteral)
-	p.registerPrefix(token.BANG, p.parsePrefixExpression)
-	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
-	p.registerPrefix(token.TRUE, p.parseBoolean)
-	p.registerPrefix(token.FALSE, p.parseBoolean)
-	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
-
-	p.infixParseFns = make(map[token.TokenType]infixParseFn)
-	p.registerInfix(token.PLUS, p.parseInfixExpression)
-	p.registerInfix(token.MINUS, p.parseInfixExpression)
-	p.registerInfix(token.SLASH, p.parseInfixExpression)
-	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
-	p.registerInfix(token.EQ, p.parseInfixExpression)
-	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
-	p.registerInfix(token.LT, p.parseInfixExpression)
-	p.registerInfix(token.GT, p.parseInfixExpression)
-
-	return p
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	defer p.trace("parseGroupedExpression", 0)()
+	g := p.arena.group()
+	*g = ast.GroupedExpression{Token: p.curToken}
 	p.nextToken()
-	exp := p.parseExpression(LOWEST)
+	g.Expression = p.parseExpression(LOWEST)
 	// expectPeek が真のとき ')' トークンがスキップされる
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
-	return exp
+	g.Rparen = p.curToken.Pos
+	return g
 }
 
 func (p *Parser) parseBoolean() ast.Expression {
-	return &ast.Boolean{
+	defer p.trace("parseBoolean", 0)()
+	b := p.arena.boolean()
+	*b = ast.Boolean{
 		Token: p.curToken, Value: p.curTokenIs(token.TRUE),
 	}
+	return b
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{
+	defer p.trace("parseIdentifier", 0)()
+	ident := p.arena.identifier()
+	*ident = ast.Identifier{
 		Token: p.curToken, Value: p.curToken.Literal,
 	}
+	return ident
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
-	literal := &ast.IntegerLiteral{Token: p.curToken}
+	defer p.trace("parseIntegerLiteral", 0)()
+	literal := p.arena.integer()
+	*literal = ast.IntegerLiteral{Token: p.curToken}
 
 	v, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		s := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, s)
+		p.errorf(p.curToken, ErrInvalidInteger, p.curToken.Literal)
 		return nil
 	}
 	literal.Value = v
@@ -117,7 +190,9 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 // '!' はこの関数で解析される
 // '!' は右結合になる
 func (p *Parser) parsePrefixExpression() ast.Expression {
-	exp := &ast.PrefixExpression{
+	defer p.trace("parsePrefixExpression", 0)()
+	exp := p.arena.prefix()
+	*exp = ast.PrefixExpression{
 		Token:    p.curToken,
 		Operator: p.curToken.Literal,
 	}
@@ -129,7 +204,9 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 // '+' や '*' はこの関数で解析される
 // '+' は左結合になる(= 解析済みの式は '+' に吸い込まれる)
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
-	exp := &ast.InfixExpression{
+	defer p.trace("parseInfixExpression", p.curPrecedence())()
+	exp := p.arena.infix()
+	*exp = ast.InfixExpression{
 		Token:    p.curToken,
 		Operator: p.curToken.Literal,
 		Left:     left,
@@ -153,15 +230,25 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
-func (p *Parser) registerPrefix(t token.TokenType, f prefixParseFn) {
-	p.prefixParseFns[t] = f
+func registerPrefix(t token.TokenType, f prefixParseFn) {
+	prefixParseFns[t] = f
 }
 
-func (p *Parser) registerInfix(t token.TokenType, f infixParseFn) {
-	p.infixParseFns[t] = f
+func registerInfix(t token.TokenType, f infixParseFn) {
+	infixParseFns[t] = f
 }
 
+// Errors はエラーメッセージだけを返す
+// 位置も必要なときは ErrorList を使う
 func (p *Parser) Errors() []string {
+	var s []string
+	for _, e := range p.errors {
+		s = append(s, e.Msg)
+	}
+	return s
+}
+
+func (p *Parser) ErrorList() ErrorList {
 	return p.errors
 }
 
@@ -174,7 +261,7 @@ func (p *Parser) nextToken() {
 func (p *Parser) ParseProgram() *ast.Program {
 	prg := &ast.Program{}
 	for p.curToken.Type != token.EOF {
-		stmt := p.parseStatement()
+		stmt := p.parseTopLevelStatement()
 		if stmt != nil {
 			prg.Statements = append(prg.Statements, stmt)
 		}
@@ -183,20 +270,45 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return prg
 }
 
+// parseTopLevelStatement は文を 1 つ構文解析し、まとまりの先頭がキーワードの綴り誤りのときは
+// まとまりの中の最初のエラーに候補を付ける
+func (p *Parser) parseTopLevelStatement() ast.Statement {
+	if !p.runOpen {
+		p.run, p.runErrs, p.runOpen = p.curToken, len(p.errors), true
+	}
+	s := p.parseStatement()
+	p.hintKeyword(p.run, p.runErrs)
+	if p.curTokenIs(token.SEMICOLON) {
+		p.runOpen = false
+	}
+	return s
+}
+
 func (p *Parser) parseStatement() ast.Statement {
+	// nil の *ast.LetStatement をそのまま返すと
+	// nil ではない ast.Statement になってしまうので注意する
 	switch p.curToken.Type {
 	case token.LET:
-		return p.parseLetStatement()
+		if s := p.parseLetStatement(); s != nil {
+			return s
+		}
 	case token.RETURN:
-		return p.parseReturnStatement()
+		if s := p.parseReturnStatement(); s != nil {
+			return s
+		}
 	default:
-		return p.parseExpressionStatement()
+		if s := p.parseExpressionStatement(); s != nil {
+			return s
+		}
 	}
+	return nil
 }
 
 func (p *Parser) parseLetStatement() *ast.LetStatement {
+	defer p.trace("parseLetStatement", 0)()
 	// e.g. 'let x = 10;'
-	let := &ast.LetStatement{Token: p.curToken}
+	let := p.arena.let()
+	*let = ast.LetStatement{Token: p.curToken}
 
 	// 次の Token が INDENT のときは
 	// curToken に INDENT を読み込ませる
@@ -204,21 +316,25 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
-	let.Name = &ast.Identifier{
+	let.Name = p.arena.identifier()
+	*let.Name = ast.Identifier{
 		Token: p.curToken,
 		Value: p.curToken.Literal,
 	}
 
 	// '='
 	if !p.expectPeek(token.ASSIGN) {
+		p.help(message.HelpLetForm, let.Name.Value)
 		return nil
 	}
 
-	// TODO:
-	// セミコロンに遭遇するまで式を読み飛ばしている
-	// セミコロンが出現しないときは?
-	for !p.curTokenIs(token.SEMICOLON) {
+	p.nextToken()
+	let.Value = p.parseExpression(LOWEST)
+
+	// セミコロンは省略できる
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
+		let.Semicolon = p.curToken.Pos
 	}
 
 	return let
@@ -232,9 +348,13 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
 
+// 字句解析できなかった文字は、期待したトークンとの違いよりも文字そのものを報告する
 func (p *Parser) peekError(t token.TokenType) {
-	s := fmt.Sprintf("expected next token to be %q, got %q instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, s)
+	if p.peekTokenIs(token.ILLEGAL) {
+		p.errorf(p.peekToken, ErrIllegalCharacter, p.peekToken.Literal)
+		return
+	}
+	p.errorf(p.peekToken, ErrUnexpectedToken, t, p.peekToken.Type)
 }
 
 func (p *Parser) expectPeek(t token.TokenType) bool {
@@ -247,37 +367,49 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 }
 
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+	defer p.trace("parseReturnStatement", 0)()
 	// e.g. 'return 10;'
-	r := &ast.ReturnStatement{Token: p.curToken}
+	r := p.arena.ret()
+	*r = ast.ReturnStatement{Token: p.curToken}
 
 	p.nextToken()
+	r.ReturnValue = p.parseExpression(LOWEST)
 
-	// TODO:
-	// セミコロンに遭遇するまで式を読み飛ばしている
-	for !p.curTokenIs(token.SEMICOLON) {
+	// セミコロンは省略できる
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
+		r.Semicolon = p.curToken.Pos
 	}
 	return r
 }
 
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+	defer p.trace("parseExpressionStatement", 0)()
 	// e.g. 'foobar;'
-	es := &ast.ExpressionStatement{Token: p.curToken}
+	es := p.arena.exprStmt()
+	*es = ast.ExpressionStatement{Token: p.curToken}
 	es.Expression = p.parseExpression(LOWEST)
 	for p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
+		es.Semicolon = p.curToken.Pos
 	}
 	return es
 }
 
 // Pratt 構文解析
 func (p *Parser) parseExpression(precedence int) ast.Expression {
-	prefix := p.prefixParseFns[p.curToken.Type]
+	defer p.trace("parseExpression", precedence)()
+	prefix := prefixParseFns[p.curToken.Type]
 	if prefix == nil {
+		// 字句解析できなかった文字は、式が無いことよりも文字そのものを報告する
+		if p.curTokenIs(token.ILLEGAL) {
+			p.errorf(p.curToken, ErrIllegalCharacter, p.curToken.Literal)
+			return nil
+		}
 		p.noPrefixParseFnError(p.curToken.Type)
 		return nil
 	}
-	leftExp := prefix()
+	leftExp := prefix(p)
 
 	// 引数で渡された優先順位より現在のトークンの `1 つ先のトークン` の優先順位が高い間、処理を繰り返す
 	// 式の解析は LOWEST から始まり、セミコロン直前まで読み取る
@@ -286,32 +418,67 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	// ただし peekTokenIs(token.SEMICOLON) を記述したほうが、セミコロンが式終端デリミタとして
 	// わかりやすくなり、理解もしやすい
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
-		infix := p.infixParseFns[p.peekToken.Type]
+		infix := infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
 		}
 		p.nextToken()
-		leftExp = infix(leftExp)
+		leftExp = infix(p, leftExp)
 	}
 
 	return leftExp
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	s := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, s)
+	p.errorf(p.curToken, ErrNoPrefixParseFn, t)
 }
 
 func (p *Parser) peekPrecedence() int {
-	if pre, ok := precedences[p.peekToken.Type]; ok {
-		return pre
-	}
-	return LOWEST
+	return precedenceOf(p.peekToken.Type)
 }
 
 func (p *Parser) curPrecedence() int {
-	if pre, ok := precedences[p.curToken.Type]; ok {
+	return precedenceOf(p.curToken.Type)
+}
+
+func precedenceOf(t token.TokenType) int {
+	if pre := precedences[t]; pre != 0 {
 		return pre
 	}
 	return LOWEST
 }
+
+// ParseExpr は src をちょうど 1 つの式として構文解析する
+// 式の後にセミコロンなどのトークンが残っているときはエラーにする
+// エラーは ErrorList として返す
+func ParseExpr(src string, opts ...Option) (ast.Expression, error) {
+	return ParseExprPrec(src, LOWEST, opts...)
+}
+
+// ParseExprPrec は precedence より強く結合する演算子だけを含む式として src を構文解析する
+// 他の言語に埋め込んだ部分式を、その言語の演算子の優先順位に合わせて解析するときに使う
+// e.g. precedence が SUM のとき 'a * b' は解析できるが、'a + b' は '+' が残るのでエラーになる
+func ParseExprPrec(src string, precedence int, opts ...Option) (ast.Expression, error) {
+	return New(lexer.New(src, lexerOptions(opts)...), opts...).parseExprPrec(precedence)
+}
+
+// ParseExpr は p の入力をちょうど 1 つの式として構文解析する
+// Reset と組み合わせると、Parser を使い回して小さな式をたくさん構文解析できる
+func (p *Parser) ParseExpr() (ast.Expression, error) {
+	return p.parseExprPrec(LOWEST)
+}
+
+func (p *Parser) parseExprPrec(precedence int) (ast.Expression, error) {
+	e := p.parseExpression(precedence)
+	switch {
+	case len(p.errors) != 0 || p.peekTokenIs(token.EOF):
+	case p.peekTokenIs(token.ILLEGAL):
+		p.errorf(p.peekToken, ErrIllegalCharacter, p.peekToken.Literal)
+	default:
+		p.errorf(p.peekToken, ErrTrailingToken, p.peekToken.Literal)
+	}
+	if err := p.errors.Err(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
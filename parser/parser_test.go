@@ -2,10 +2,12 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hiroygo/go-interpreter/ast"
 	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/token"
 )
 
 func TestLetStatements(t *testing.T) {
@@ -13,15 +15,16 @@ func TestLetStatements(t *testing.T) {
 	// 文字列の方がテストが読みやすく、理解しやすいため
 	input := `
 let x = 5;
-let y = 10;
-let foobar = 838383;
+let y = true;
+let foobar = y
 `
 	tests := []struct {
 		expectedIdentifier string
+		expectedValue      interface{}
 	}{
-		{"x"},
-		{"y"},
-		{"foobar"},
+		{"x", 5},
+		{"y", true},
+		{"foobar", "y"},
 	}
 
 	p := New(lexer.New(input))
@@ -33,6 +36,7 @@ let foobar = 838383;
 
 	for i, tt := range tests {
 		testLetStatement(t, prg.Statements[i], tt.expectedIdentifier)
+		testLiteralExpression(t, prg.Statements[i].(*ast.LetStatement).Value, tt.expectedValue)
 	}
 }
 
@@ -57,8 +61,8 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) {
 func TestReturnStatements(t *testing.T) {
 	input := `
 return 5;
-return 10;
-return 993322;
+return x;
+return 993322
 `
 	p := New(lexer.New(input))
 	prg := p.ParseProgram()
@@ -446,3 +450,240 @@ func TestBooleanExpression(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeSpans(t *testing.T) {
+	input := "let x = 5;\n(a + b) * -c;\nreturn 1;"
+	cases := []struct {
+		// 文の番号
+		stmt     int
+		expected string
+	}{
+		{0, "let x = 5;"},
+		{1, "(a + b) * -c;"},
+		{2, "return 1;"},
+	}
+
+	p := New(lexer.New(input))
+	prg := p.ParseProgram()
+	hasParserErrors(t, p)
+	if len(prg.Statements) != len(cases) {
+		t.Fatalf("want len(Program.Statements) = %v, got %v", len(cases), len(prg.Statements))
+	}
+
+	for _, c := range cases {
+		s := prg.Statements[c.stmt]
+		actual := input[s.Pos().Offset():s.End().Offset()]
+		if actual != c.expected {
+			t.Fatalf("want %T span = %q, got %q", s, c.expected, actual)
+		}
+	}
+	if prg.Pos().Offset() != 0 || prg.End().Offset() != len(input) {
+		t.Fatalf("want Program span = [0, %d), got [%d, %d)", len(input), prg.Pos().Offset(), prg.End().Offset())
+	}
+
+	stmt := prg.Statements[1].(*ast.ExpressionStatement)
+	infix, ok := stmt.Expression.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("%T.(*ast.InfixExpression) error", stmt.Expression)
+	}
+	// 子ノードの範囲は親ノードの範囲に含まれる
+	spans := []struct {
+		node     ast.Node
+		expected string
+	}{
+		{infix, "(a + b) * -c"},
+		{infix.Left, "(a + b)"},
+		{infix.Left.(*ast.GroupedExpression).Expression, "a + b"},
+		{infix.Right, "-c"},
+	}
+	for _, sp := range spans {
+		actual := input[sp.node.Pos().Offset():sp.node.End().Offset()]
+		if actual != sp.expected {
+			t.Fatalf("want %T span = %q, got %q", sp.node, sp.expected, actual)
+		}
+	}
+}
+
+func TestKeywordHints(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{"lett x = 5;", []string{
+			"no prefix parse function for = found: unknown identifier `lett`; did you mean keyword `let`?",
+		}},
+		{"fun(x) { x }", []string{
+			"no prefix parse function for { found: unknown identifier `fun`; did you mean keyword `fn`?",
+			"no prefix parse function for } found",
+		}},
+		// 候補は ';' で区切ったまとまりの中のエラーにだけ付ける
+		{"lett; x = 5;", []string{
+			"no prefix parse function for = found",
+		}},
+		// 綴りが似ているだけではエラーにしない
+		// 'retrun' の候補は resolver が未定義の名前の診断に付ける
+		{"retrun 5;", nil},
+		{"f 5; i (2);", nil},
+		{"foo 5;", nil},
+		{"retrun;", nil},
+	}
+
+	for _, c := range cases {
+		p := New(lexer.New(c.input))
+		p.ParseProgram()
+		var actual []string
+		for _, e := range p.ErrorList() {
+			msg := e.Msg
+			for _, h := range e.Hints {
+				msg += ": " + h.String()
+			}
+			actual = append(actual, msg)
+		}
+		if strings.Join(actual, "\n") != strings.Join(c.expected, "\n") {
+			t.Errorf("%q: want errors %q, got %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestParseExpr(t *testing.T) {
+	cases := []struct {
+		input      string
+		precedence int
+		expected   string
+		err        string
+	}{
+		{"a + b * 2", LOWEST, "(a + (b * 2))", ""},
+		{" -(x) ", LOWEST, "(-x)", ""},
+		{"a * b", SUM, "(a * b)", ""},
+		{"a + b", SUM, "", `unexpected "+" after the expression`},
+		{"a + 1;", LOWEST, "", `unexpected ";" after the expression`},
+		{"a b", LOWEST, "", `unexpected "b" after the expression`},
+		{"", LOWEST, "", "no prefix parse function for EOF found"},
+		{"(a", LOWEST, "", `expected next token to be ")", got "EOF" instead`},
+	}
+
+	for _, c := range cases {
+		e, err := ParseExprPrec(c.input, c.precedence)
+		if c.err != "" {
+			errs, ok := err.(ErrorList)
+			if !ok || len(errs) == 0 || errs[0].Msg != c.err {
+				t.Fatalf("ParseExprPrec(%q): want error %q, got %v", c.input, c.err, err)
+			}
+			if e != nil {
+				t.Fatalf("ParseExprPrec(%q): want nil expression, got %s", c.input, e)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseExprPrec(%q): %v", c.input, err)
+		}
+		if e.String() != c.expected {
+			t.Fatalf("ParseExprPrec(%q): want %s, got %s", c.input, c.expected, e)
+		}
+	}
+
+	// 既定の優先順位は LOWEST
+	if _, err := ParseExpr("1 == 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lexer の設定は WithLexerOptions で渡す
+	if e, err := ParseExpr("!真", WithLexerOptions(lexer.WithDialect(token.Japanese))); err != nil || e.String() != "(!真)" {
+		t.Fatalf("ParseExpr(!真): want (!真), got %v, %v", e, err)
+	}
+	_, err := ParseExpr("1;")
+	if errs := err.(ErrorList); errs[0].Code != ErrTrailingToken || errs[0].Pos.Offset() != 1 {
+		t.Fatalf("want %s at offset 1, got %+v", ErrTrailingToken, errs[0])
+	}
+}
+
+func TestReset(t *testing.T) {
+	l := lexer.New("let = 1;")
+	p := New(l)
+	p.ParseProgram()
+	errs := p.ErrorList()
+	if len(errs) == 0 {
+		t.Fatal("want an error for the first input")
+	}
+
+	l.Reset("let x = 1 + 2; x;")
+	p.Reset(l)
+	prg := p.ParseProgram()
+	hasParserErrors(t, p)
+	if got := prg.String(); got != "let x = (1 + 2);x" {
+		t.Fatalf("want the second input, got %q", got)
+	}
+	if len(errs) == 0 || errs[0].Code != ErrUnexpectedToken {
+		t.Fatalf("want the previous ErrorList unchanged, got %v", errs)
+	}
+}
+
+func TestArena(t *testing.T) {
+	inputs := []string{
+		"let x = 5 * (a + b); return !x;",
+		"-a * b == c; if;",
+		"let y = true != false; y",
+	}
+	a := NewArena()
+	for _, input := range inputs {
+		want := New(lexer.New(input)).ParseProgram().String()
+
+		a.Reset()
+		// チャンクが足りなくなるまで繰り返し構文解析しても、以前のノードは書き換わらない
+		var prgs []*ast.Program
+		for i := 0; i < arenaChunk; i++ {
+			prgs = append(prgs, New(lexer.New(input), WithArena(a)).ParseProgram())
+		}
+		for _, prg := range prgs {
+			if got := prg.String(); got != want {
+				t.Fatalf("%q: want %q with an Arena, got %q", input, want, got)
+			}
+		}
+	}
+}
+
+func TestParseExprReuseAllocs(t *testing.T) {
+	a := NewArena()
+	l := lexer.New("")
+	p := New(l, WithArena(a))
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Reset()
+		l.Reset("-count * (total - 1) / 3 < limit")
+		p.Reset(l)
+		if _, err := p.ParseExpr(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("want no allocations when reusing a Parser with an Arena, got %v", allocs)
+	}
+}
+
+func TestIllegalCharacter(t *testing.T) {
+	cases := []struct {
+		input string
+		pos   int
+	}{
+		// 式の位置
+		{"1 + @;", 4},
+		// let の名前の位置
+		{"let @ = 1;", 4},
+		// let の '=' の位置
+		{"let x @ 1;", 6},
+		// ')' の位置
+		{"(a @", 3},
+	}
+	for _, c := range cases {
+		p := New(lexer.New(c.input))
+		p.ParseProgram()
+		errs := p.ErrorList()
+		if len(errs) == 0 || errs[0].Code != ErrIllegalCharacter || errs[0].Pos != token.PosFromOffset(c.pos) {
+			t.Errorf("%q: want %s at offset %d, got %v", c.input, ErrIllegalCharacter, c.pos, errs)
+		}
+	}
+
+	_, err := ParseExpr("a @")
+	if errs, ok := err.(ErrorList); !ok || len(errs) != 1 || errs[0].Code != ErrIllegalCharacter {
+		t.Errorf("ParseExpr(a @): want %s, got %v", ErrIllegalCharacter, err)
+	}
+}
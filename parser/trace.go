@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// TraceKind は TraceEvent が関数の始まりと終わりのどちらかを表す
+type TraceKind int
+
+const (
+	TraceBegin TraceKind = iota
+	TraceEnd
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceBegin:
+		return "BEGIN"
+	case TraceEnd:
+		return "END"
+	}
+	return fmt.Sprintf("TraceKind(%d)", int(k))
+}
+
+// TraceEvent は構文解析の関数の呼び出しを表す
+type TraceEvent struct {
+	Kind TraceKind
+	// 構文解析の関数の名前
+	// e.g. 'parseInfixExpression'
+	Func string
+	// 呼び出しの深さ
+	// 一番外側は 0
+	Depth int
+	// parseExpression では引数の優先順位、parseInfixExpression では演算子の優先順位
+	// それ以外の関数では 0
+	Precedence int
+	// 呼び出したときと戻るときのトークン
+	Cur  token.Token
+	Peek token.Token
+	// Peek を中置演算子として見たときの優先順位
+	PeekPrecedence int
+}
+
+// String は 'BEGIN parseExpression precedence=LOWEST cur="-" peek="a" (LOWEST)' の形式で返す
+// 括弧の中は peek の優先順位で、字下げは含めない
+func (e TraceEvent) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", e.Kind, e.Func)
+	if e.Precedence != 0 {
+		fmt.Fprintf(&b, " precedence=%s", PrecedenceName(e.Precedence))
+	}
+	fmt.Fprintf(&b, " cur=%s peek=%s (%s)", traceToken(e.Cur), traceToken(e.Peek), PrecedenceName(e.PeekPrecedence))
+	return b.String()
+}
+
+// traceToken はリテラルが空の EOF を種類の名前で表す
+func traceToken(t token.Token) string {
+	if t.Type == token.EOF {
+		return "EOF"
+	}
+	return fmt.Sprintf("%q", t.Literal)
+}
+
+// PrecedenceName は優先順位の定数の名前を返す
+// e.g. 'LOWEST'
+func PrecedenceName(precedence int) string {
+	switch precedence {
+	case LOWEST:
+		return "LOWEST"
+	case EQUALS:
+		return "EQUALS"
+	case LESSGREATER:
+		return "LESSGREATER"
+	case SUM:
+		return "SUM"
+	case PRODUCT:
+		return "PRODUCT"
+	case PREFIX:
+		return "PREFIX"
+	case CALL:
+		return "CALL"
+	}
+	return fmt.Sprintf("%d", precedence)
+}
+
+// WithTracer は構文解析の関数を呼び出すたびに f に TraceEvent を渡す
+func WithTracer(f func(TraceEvent)) Option {
+	return func(p *Parser) {
+		p.tracer = f
+	}
+}
+
+// WithTrace は TraceEvent を呼び出しの深さだけタブで字下げして w に出力する
+func WithTrace(w io.Writer) Option {
+	return WithTracer(func(e TraceEvent) {
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat("\t", e.Depth), e)
+	})
+}
+
+// trace は関数の始まりを記録し、終わりを記録する関数を返す
+// e.g. 'defer p.trace("parseExpression", precedence)()'
+func (p *Parser) trace(fn string, precedence int) func() {
+	if p.tracer == nil {
+		return func() {}
+	}
+	p.emit(TraceBegin, fn, precedence)
+	p.traceDepth++
+	return func() {
+		p.traceDepth--
+		p.emit(TraceEnd, fn, precedence)
+	}
+}
+
+func (p *Parser) emit(kind TraceKind, fn string, precedence int) {
+	p.tracer(TraceEvent{
+		Kind:           kind,
+		Func:           fn,
+		Depth:          p.traceDepth,
+		Precedence:     precedence,
+		Cur:            p.curToken,
+		Peek:           p.peekToken,
+		PeekPrecedence: p.peekPrecedence(),
+	})
+}
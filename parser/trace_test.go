@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/lexer"
+)
+
+func TestTracer(t *testing.T) {
+	var events []TraceEvent
+	p := New(lexer.New("-a * b + c"), WithTracer(func(e TraceEvent) {
+		events = append(events, e)
+	}))
+	p.ParseProgram()
+	hasParserErrors(t, p)
+
+	// '*' は '-a' を左辺に、'+' は '((-a) * b)' を左辺にとる
+	var actual []string
+	for _, e := range events {
+		if e.Kind == TraceBegin && e.Func == "parseInfixExpression" {
+			actual = append(actual, fmt.Sprintf("%s@%d:%s", e.Cur.Literal, e.Depth, PrecedenceName(e.Precedence)))
+		}
+	}
+	expected := []string{"*@2:PRODUCT", "+@2:SUM"}
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Fatalf("want infix events %q, got %q", expected, actual)
+	}
+
+	// BEGIN と END は対になる
+	depth := 0
+	for _, e := range events {
+		if e.Kind == TraceBegin {
+			if e.Depth != depth {
+				t.Fatalf("want BEGIN depth %d, got %+v", depth, e)
+			}
+			depth++
+		} else {
+			depth--
+			if e.Depth != depth {
+				t.Fatalf("want END depth %d, got %+v", depth, e)
+			}
+		}
+	}
+	if depth != 0 {
+		t.Fatalf("want balanced events, got depth %d", depth)
+	}
+}
+
+func TestWithTrace(t *testing.T) {
+	var b strings.Builder
+	p := New(lexer.New("1"), WithTrace(&b))
+	p.ParseProgram()
+	expected := "BEGIN parseExpressionStatement cur=\"1\" peek=EOF (LOWEST)\n" +
+		"\tBEGIN parseExpression precedence=LOWEST cur=\"1\" peek=EOF (LOWEST)\n" +
+		"\t\tBEGIN parseIntegerLiteral cur=\"1\" peek=EOF (LOWEST)\n" +
+		"\t\tEND parseIntegerLiteral cur=\"1\" peek=EOF (LOWEST)\n" +
+		"\tEND parseExpression precedence=LOWEST cur=\"1\" peek=EOF (LOWEST)\n" +
+		"END parseExpressionStatement cur=\"1\" peek=EOF (LOWEST)\n"
+	if b.String() != expected {
+		t.Fatalf("want trace\n%s\ngot\n%s", expected, b.String())
+	}
+}
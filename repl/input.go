@@ -0,0 +1,60 @@
+package repl
+
+import (
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// この種類のトークンで終わる入力は、後に式が続くはず
+var continuationTokens = map[token.TokenType]bool{
+	token.ASSIGN:   true,
+	token.PLUS:     true,
+	token.MINUS:    true,
+	token.BANG:     true,
+	token.ASTERISK: true,
+	token.SLASH:    true,
+	token.LT:       true,
+	token.GT:       true,
+	token.EQ:       true,
+	token.NOT_EQ:   true,
+	token.COMMA:    true,
+	token.LET:      true,
+	token.RETURN:   true,
+}
+
+// isIncomplete は src が途中までしか入力されていないときに true を返す
+// 括弧が閉じていないときと、文が演算子などで終わっているときに続きの入力を待つ
+// 閉じ括弧が多すぎるときは、続きを入力しても直らないので false を返す
+func isIncomplete(src string) bool {
+	depth := 0
+	var prev, last token.Token
+	for t := range lexer.Tokens(src) {
+		switch t.Type {
+		case token.LPAREN, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACE:
+			depth--
+		case token.ILLEGAL:
+			// '[' と ']' はまだトークンになっていない
+			switch t.Literal {
+			case "[":
+				depth++
+			case "]":
+				depth--
+			}
+		}
+		if depth < 0 {
+			return false
+		}
+		prev, last = last, t
+	}
+
+	if depth > 0 {
+		return true
+	}
+	if continuationTokens[last.Type] {
+		return true
+	}
+	// 'let x' の後には '=' が続くはず
+	return prev.Type == token.LET && last.Type == token.IDENT
+}
@@ -4,25 +4,171 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/hiroygo/go-interpreter/diagnostic"
+	"github.com/hiroygo/go-interpreter/evaluator"
 	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/object"
+	"github.com/hiroygo/go-interpreter/parser"
 	"github.com/hiroygo/go-interpreter/token"
 )
 
-const PROMPT = ">> "
+const (
+	PROMPT = ">> "
+	// 入力が途中までのときに表示する
+	CONTINUATION_PROMPT = ".. "
+)
+
+// mode は入力をどこまで処理して表示するかを表す
+type mode int
+
+const (
+	// 評価した結果を表示する
+	modeEval mode = iota
+	// 構文解析した Program.String() を表示する
+	modeAST
+	// 字句解析したトークンを表示する
+	modeTokens
+)
+
+// ':' から始まる入力はコマンドとして扱う
+var commands = map[string]mode{
+	":eval":   modeEval,
+	":ast":    modeAST,
+	":tokens": modeTokens,
+}
+
+type session struct {
+	out  io.Writer
+	mode mode
+	// 評価モードで let した値は次の入力でも使える
+	env *object.Environment
+	// 構文エラーをソースの抜粋付きで表示する
+	diag *diagnostic.Renderer
+	// 構文エラーの言語
+	lang parser.Language
+	// 構文解析の関数の呼び出しを表示する
+	trace bool
+}
 
 func Start(in io.Reader, out io.Writer) {
+	s := &session{
+		out:  out,
+		mode: modeEval,
+		env:  object.NewEnvironment(),
+		diag: diagnostic.NewRenderer(out),
+		lang: parser.LanguageFromEnv(),
+	}
 	sc := bufio.NewScanner(in)
+
+	// 括弧が閉じるまでなど、入力が完成するまで行を貯めておく
+	var lines []string
+	// 続きの入力中に連続した空行の数
+	emptyLines := 0
 	for {
-		fmt.Print(PROMPT)
+		if len(lines) == 0 {
+			fmt.Fprint(out, PROMPT)
+		} else {
+			fmt.Fprint(out, CONTINUATION_PROMPT)
+		}
 		if !sc.Scan() {
 			return
 		}
 
 		line := sc.Text()
-		l := lexer.New(line)
-		for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
-			fmt.Printf("%+v\n", t)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == ":reset" {
+			lines = nil
+			emptyLines = 0
+			continue
+		}
+		if len(lines) == 0 {
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasPrefix(trimmed, ":") {
+				s.command(trimmed)
+				continue
+			}
+		}
+
+		// 空行を 2 回続けると入力途中のものを捨てる
+		if trimmed == "" {
+			emptyLines++
+			if emptyLines >= 2 {
+				lines = nil
+				emptyLines = 0
+				fmt.Fprintln(out, "input discarded")
+			}
+			continue
 		}
+		emptyLines = 0
+
+		lines = append(lines, line)
+		src := strings.Join(lines, "\n")
+		if isIncomplete(src) {
+			continue
+		}
+		lines = nil
+		s.run(src)
+	}
+}
+
+func (s *session) command(cmd string) {
+	// ':trace on' と ':trace off' はモードとは別に切り替える
+	switch strings.Join(strings.Fields(cmd), " ") {
+	case ":trace on":
+		s.trace = true
+		return
+	case ":trace off":
+		s.trace = false
+		return
+	}
+
+	m, ok := commands[cmd]
+	if !ok {
+		fmt.Fprintf(s.out, "unknown command %q, available: :eval, :ast, :tokens, :trace on|off, :reset\n", cmd)
+		return
+	}
+	s.mode = m
+}
+
+func (s *session) run(line string) {
+	if s.mode == modeTokens {
+		for t := range lexer.Tokens(line) {
+			fmt.Fprintf(s.out, "%+v\n", t)
+		}
+		return
+	}
+
+	opts := []parser.Option{parser.WithLanguage(s.lang)}
+	if s.trace {
+		opts = append(opts, parser.WithTrace(s.out))
+	}
+	p := parser.New(lexer.New(line), opts...)
+	prg := p.ParseProgram()
+	if errs := p.ErrorList(); len(errs) != 0 {
+		s.diag.RenderParserErrors(s.out, token.NewFile("", line), line, errs)
+		return
+	}
+
+	if s.mode == modeAST {
+		fmt.Fprintln(s.out, prg.String())
+		return
+	}
+
+	// 前の入力までに束縛した名前は、この入力の外側で宣言されたものとして扱う
+	names := s.env.Names()
+	o := evaluator.Eval(prg, s.env)
+	if e, ok := o.(*object.Error); ok {
+		if d, ok := diagnostic.FromUndefined(prg, names, e, s.lang); ok {
+			s.diag.Render(s.out, token.NewFile("", line), line, d)
+			return
+		}
+	}
+	// let 文だけのときは値が無いので何も出力しない
+	if o != evaluator.NULL {
+		fmt.Fprintln(s.out, o.Inspect())
 	}
 }
@@ -0,0 +1,134 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStart(t *testing.T) {
+	// 構文エラーのメッセージを英語にする
+	t.Setenv("LC_ALL", "C")
+
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"eval",
+			"let x = 2;\nx * 3\n",
+			">> >> 6\n>> ",
+		},
+		{
+			"ast",
+			":ast\n-a * b\n",
+			">> >> ((-a) * b)\n>> ",
+		},
+		{
+			"tokens",
+			":tokens\nx;\n",
+			">> >> {Type:IDENT Literal:x Pos:1}\n{Type:; Literal:; Pos:2}\n>> ",
+		},
+		{
+			"parser errors",
+			"let x 5;\n",
+			">> error[E0001]: expected next token to be \"=\", got \"INT\" instead\n" +
+				" --> 1:7\n" +
+				"  |\n" +
+				"1 | let x 5;\n" +
+				"  |       ^\n" +
+				"  = help: a let statement has the form 'let x = <expression>;'\n>> ",
+		},
+		{
+			"runtime error",
+			"1 + true\n",
+			">> ERROR: type mismatch: INTEGER + BOOLEAN\n>> ",
+		},
+		{
+			"undefined name",
+			"let total = 1;\ntotl + 1\n",
+			">> >> error: undefined: totl\n" +
+				" --> 1:1\n" +
+				"  |\n" +
+				"1 | totl + 1\n" +
+				"  | ^^^^\n" +
+				"  = help: unknown identifier `totl`; did you mean identifier `total`?\n>> ",
+		},
+		{
+			"unknown command",
+			":foo\n",
+			">> unknown command \":foo\", available: :eval, :ast, :tokens, :trace on|off, :reset\n>> ",
+		},
+		{
+			"trace",
+			":trace on\n:ast\n-a\n:trace off\n-a\n",
+			">> >> >> BEGIN parseExpressionStatement cur=\"-\" peek=\"a\" (LOWEST)\n" +
+				"\tBEGIN parseExpression precedence=LOWEST cur=\"-\" peek=\"a\" (LOWEST)\n" +
+				"\t\tBEGIN parsePrefixExpression cur=\"-\" peek=\"a\" (LOWEST)\n" +
+				"\t\t\tBEGIN parseExpression precedence=PREFIX cur=\"a\" peek=EOF (LOWEST)\n" +
+				"\t\t\t\tBEGIN parseIdentifier cur=\"a\" peek=EOF (LOWEST)\n" +
+				"\t\t\t\tEND parseIdentifier cur=\"a\" peek=EOF (LOWEST)\n" +
+				"\t\t\tEND parseExpression precedence=PREFIX cur=\"a\" peek=EOF (LOWEST)\n" +
+				"\t\tEND parsePrefixExpression cur=\"a\" peek=EOF (LOWEST)\n" +
+				"\tEND parseExpression precedence=LOWEST cur=\"a\" peek=EOF (LOWEST)\n" +
+				"END parseExpressionStatement cur=\"a\" peek=EOF (LOWEST)\n" +
+				"(-a)\n>> >> (-a)\n>> ",
+		},
+		{
+			"continuation",
+			"(1 +\n2) *\n\n3\nlet x\n= 4; x\n",
+			">> .. .. .. 9\n>> .. 4\n>> ",
+		},
+		{
+			"discard with empty lines",
+			"1 + (2\n\n\n5\n",
+			">> .. .. input discarded\n>> 5\n>> ",
+		},
+		{
+			"discard with reset",
+			"1 +\n:reset\n:ast\n-a\n",
+			">> .. >> >> (-a)\n>> ",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			Start(strings.NewReader(c.input), &out)
+			if out.String() != c.expected {
+				t.Fatalf("want output %q, got %q", c.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestIsIncomplete(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 + 2", false},
+		{"1 + 2;", false},
+		{"1 +", true},
+		{"(1 + 2", true},
+		{"{", true},
+		{"[1, 2", true},
+		{"let", true},
+		{"let x", true},
+		{"let x =", true},
+		{"return", true},
+		{"-", true},
+		{"1 + 2)", false},
+		{"(1 + 2))(", false},
+	}
+
+	for _, c := range cases {
+		if actual := isIncomplete(c.input); actual != c.expected {
+			t.Errorf("want isIncomplete(%q) = %t, got %t", c.input, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// 書き込みが進まない接続を閉じるまでの時間
+// IdleTimeout が 0 のときに使う
+const defaultWriteTimeout = 10 * time.Second
+
+// Server は接続ごとに独立したセッションで REPL を提供する
+// 実行中のサービスに組み込んで、TCP や Unix ソケット経由でデバッグするために使う
+type Server struct {
+	// 同時に接続できるセッションの最大数
+	// 0 以下のときは制限しない
+	MaxSessions int
+	// 入力が無いまま経過するとセッションを閉じる時間
+	// 0 以下のときは閉じない
+	IdleTimeout time.Duration
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// Serve は ln で接続を受け付け、接続ごとに Start を実行する
+// ctx がキャンセルされると ln とすべての接続を閉じ、セッションの終了を待ってから nil を返す
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ln.Close()
+		case <-stop:
+		}
+	}()
+
+	defer s.wg.Wait()
+	defer s.closeAll()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if !s.track(conn) {
+			// 読まないクライアントへの書き込みで、ほかの接続の受け付けを止めない
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				reject(conn)
+			}()
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(conn)
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// reject はセッション数が上限に達していることを伝えて conn を閉じる
+func reject(conn net.Conn) {
+	defer conn.Close()
+	conn.SetWriteDeadline(time.Now().Add(time.Second))
+	fmt.Fprintln(conn, "too many sessions")
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	c := &deadlineConn{Conn: conn, idleTimeout: s.IdleTimeout}
+	Start(c, c)
+}
+
+// track は conn を記録する
+// セッション数が上限に達しているときは false を返す
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]struct{})
+	}
+	if s.MaxSessions > 0 && len(s.conns) >= s.MaxSessions {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, conn)
+}
+
+// closeAll はすべての接続を閉じる
+// 読み書き中の Start はエラーで終了する
+func (s *Server) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.Close()
+	}
+}
+
+// deadlineConn は読み書きのたびに期限を設定する
+// 入力が無いまま idleTimeout が経過すると Read がエラーを返し、セッションが終わる
+type deadlineConn struct {
+	net.Conn
+	idleTimeout time.Duration
+}
+
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if c.idleTimeout > 0 {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.idleTimeout)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *deadlineConn) Write(b []byte) (int, error) {
+	timeout := c.idleTimeout
+	if timeout <= 0 {
+		timeout = defaultWriteTimeout
+	}
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
@@ -0,0 +1,233 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pipeListener は net.Pipe の接続を受け付ける net.Listener
+type pipeListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+func (l *pipeListener) dial() net.Conn {
+	client, server := net.Pipe()
+	l.conns <- server
+	return client
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newClient(t *testing.T, conn net.Conn) *client {
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// expect は want で終わるまで出力を読む
+func (c *client) expect(want string) {
+	c.t.Helper()
+
+	var b strings.Builder
+	for !strings.HasSuffix(b.String(), want) {
+		ch, err := c.r.ReadByte()
+		if err != nil {
+			c.t.Fatalf("want output ending with %q, got %q, %v", want, b.String(), err)
+		}
+		b.WriteByte(ch)
+	}
+}
+
+func (c *client) send(line string) {
+	c.t.Helper()
+
+	if _, err := io.WriteString(c.conn, line+"\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func startServer(t *testing.T, s *Server, ln net.Listener) (context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- s.Serve(ctx, ln) }()
+	return cancel, errc
+}
+
+func TestServerIsolatedSessions(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel, errc := startServer(t, &Server{}, ln)
+	defer cancel()
+
+	dial := func() *client {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return newClient(t, conn)
+	}
+	a, b := dial(), dial()
+	defer a.conn.Close()
+	defer b.conn.Close()
+
+	a.expect(PROMPT)
+	a.send("let x = 1;")
+	a.expect(PROMPT)
+	a.send("x + 1")
+	a.expect("2\n" + PROMPT)
+
+	// a で束縛した x は b からは見えない
+	b.expect(PROMPT)
+	b.send("x")
+	b.expect("error: undefined: x\n --> 1:1\n  |\n1 | x\n  | ^\n" + PROMPT)
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("want Serve() = nil, got %v", err)
+	}
+}
+
+func TestServerMaxSessions(t *testing.T) {
+	ln := newPipeListener()
+	cancel, errc := startServer(t, &Server{MaxSessions: 1}, ln)
+	defer cancel()
+
+	a := newClient(t, ln.dial())
+	defer a.conn.Close()
+	a.expect(PROMPT)
+
+	b := newClient(t, ln.dial())
+	b.expect("too many sessions\n")
+	if _, err := b.r.ReadByte(); err != io.EOF {
+		t.Fatalf("want connection closed, got %v", err)
+	}
+
+	// セッションが終われば、次の接続を受け付ける
+	a.conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c := newClient(t, ln.dial())
+		first, err := c.r.ReadByte()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == PROMPT[0] {
+			c.conn.Close()
+			break
+		}
+		c.conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("want new session after the first one ended")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("want Serve() = nil, got %v", err)
+	}
+}
+
+func TestServerRejectDoesNotBlock(t *testing.T) {
+	ln := newPipeListener()
+	cancel, errc := startServer(t, &Server{MaxSessions: 1}, ln)
+	defer cancel()
+
+	a := newClient(t, ln.dial())
+	defer a.conn.Close()
+	a.expect(PROMPT)
+
+	// 断られたメッセージを読まないクライアントがいても、次の接続はすぐに受け付ける
+	b := ln.dial()
+	defer b.Close()
+	start := time.Now()
+	c := newClient(t, ln.dial())
+	defer c.conn.Close()
+	c.expect("too many sessions\n")
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("want the next connection accepted without waiting for the rejected one, took %v", d)
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("want Serve() = nil, got %v", err)
+	}
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	ln := newPipeListener()
+	cancel, errc := startServer(t, &Server{IdleTimeout: 50 * time.Millisecond}, ln)
+	defer cancel()
+
+	c := newClient(t, ln.dial())
+	defer c.conn.Close()
+	c.expect(PROMPT)
+	if _, err := c.r.ReadByte(); err != io.EOF {
+		t.Fatalf("want connection closed after idle timeout, got %v", err)
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("want Serve() = nil, got %v", err)
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	ln := newPipeListener()
+	cancel, errc := startServer(t, &Server{}, ln)
+
+	c := newClient(t, ln.dial())
+	defer c.conn.Close()
+	c.expect(PROMPT)
+
+	// 接続中のセッションも閉じられる
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("want Serve() = nil, got %v", err)
+	}
+	if _, err := c.r.ReadByte(); err != io.EOF {
+		t.Fatalf("want connection closed on shutdown, got %v", err)
+	}
+}
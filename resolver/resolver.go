@@ -0,0 +1,197 @@
+// resolver は識別子の使用とそれを宣言した let 文を結びつける
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/suggest"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	// シャドーイングの診断に使う
+	Warning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Diagnostic は位置付きの診断メッセージを表す
+type Diagnostic struct {
+	Pos      token.Pos
+	End      token.Pos
+	Severity Severity
+	Msg      string
+	// 未定義の名前に近い、スコープで見える名前
+	Hints []suggest.Hint
+}
+
+type RefKind int
+
+const (
+	// 同じプログラムの中で宣言された名前への参照
+	Local RefKind = iota
+	// 組み込みの名前への参照
+	Global
+	// 宣言されていない名前への参照
+	Undefined
+)
+
+func (k RefKind) String() string {
+	switch k {
+	case Local:
+		return "local"
+	case Global:
+		return "global"
+	case Undefined:
+		return "undefined"
+	}
+	return fmt.Sprintf("RefKind(%d)", int(k))
+}
+
+// Ref は識別子の使用が何を指しているかを表す
+type Ref struct {
+	Kind RefKind
+	// Undefined のときは nil
+	Decl *Decl
+}
+
+// Info は Resolve の結果を保持する
+type Info struct {
+	// 宣言している識別子と、その宣言
+	// e.g. 'let x = 1;' の 'x'
+	Defs map[*ast.Identifier]*Decl
+	// 使用している識別子と、その参照先
+	Uses map[*ast.Identifier]*Ref
+	// スコープを作ったノードと、そのスコープ
+	Scopes map[ast.Node]*Scope
+	// 未定義の名前、同じスコープでの重複した宣言、シャドーイングの診断
+	// ソースに現れる順に並ぶ
+	Diagnostics []Diagnostic
+}
+
+// Resolve は prg のスコープを作り、識別子を宣言に結びつける
+// globals は組み込みの名前で、プログラムのスコープの外側で宣言されたものとして扱う
+func Resolve(prg *ast.Program, globals []string) *Info {
+	r := &resolver{
+		info: &Info{
+			Defs:   map[*ast.Identifier]*Decl{},
+			Uses:   map[*ast.Identifier]*Ref{},
+			Scopes: map[ast.Node]*Scope{},
+		},
+	}
+
+	universe := newScope(nil, nil)
+	for _, g := range globals {
+		universe.insert(&Decl{Name: g})
+	}
+
+	r.scope = newScope(universe, prg)
+	r.info.Scopes[prg] = r.scope
+	for _, s := range prg.Statements {
+		r.statement(s)
+	}
+	return r.info
+}
+
+type resolver struct {
+	info  *Info
+	scope *Scope
+}
+
+func (r *resolver) statement(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		// 'let x = x;' の右辺の x は、これから宣言する x ではない
+		r.expression(s.Value)
+		if s.Name != nil {
+			r.declare(s.Name, s)
+		}
+	case *ast.ReturnStatement:
+		r.expression(s.ReturnValue)
+	case *ast.ExpressionStatement:
+		r.expression(s.Expression)
+	}
+}
+
+func (r *resolver) expression(e ast.Expression) {
+	if e == nil {
+		return
+	}
+	ast.Inspect(e, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Identifier); ok {
+			r.use(ident)
+		}
+		return true
+	})
+}
+
+func (r *resolver) declare(ident *ast.Identifier, node ast.Node) {
+	name := ident.Value
+	if prev := r.scope.LookupLocal(name); prev != nil {
+		r.errorf(ident, "%s redeclared in this scope", name)
+	} else if outer := r.scope.Parent.Lookup(name); outer != nil {
+		kind := "outer"
+		if outer.IsGlobal() {
+			kind = "builtin"
+		}
+		r.warnf(ident, "declaration of %s shadows %s %s", name, kind, name)
+	}
+
+	d := &Decl{Name: name, Ident: ident, Node: node}
+	r.scope.insert(d)
+	r.info.Defs[ident] = d
+}
+
+func (r *resolver) use(ident *ast.Identifier) {
+	d := r.scope.Lookup(ident.Value)
+	switch {
+	case d == nil:
+		r.info.Uses[ident] = &Ref{Kind: Undefined}
+		r.errorf(ident, "undefined: %s", ident.Value)
+		r.hint(ident)
+	case d.IsGlobal():
+		r.info.Uses[ident] = &Ref{Kind: Global, Decl: d}
+	default:
+		r.info.Uses[ident] = &Ref{Kind: Local, Decl: d}
+	}
+}
+
+func (r *resolver) errorf(n ast.Node, format string, args ...interface{}) {
+	r.report(n, Error, format, args...)
+}
+
+func (r *resolver) warnf(n ast.Node, format string, args ...interface{}) {
+	r.report(n, Warning, format, args...)
+}
+
+func (r *resolver) report(n ast.Node, sev Severity, format string, args ...interface{}) {
+	r.info.Diagnostics = append(r.info.Diagnostics, Diagnostic{
+		Pos:      n.Pos(),
+		End:      n.End(),
+		Severity: sev,
+		Msg:      fmt.Sprintf(format, args...),
+	})
+}
+
+// hint は最後の診断に ident と綴りの近い名前の候補を付ける
+// 名前が無いときはキーワードから探す
+func (r *resolver) hint(ident *ast.Identifier) {
+	d := &r.info.Diagnostics[len(r.info.Diagnostics)-1]
+	if name, ok := suggest.Closest(ident.Value, r.scope.Names()); ok {
+		d.Hints = append(d.Hints, suggest.Hint{Name: ident.Value, Suggestion: name})
+	} else if kw, ok := suggest.Closest(ident.Value, token.Keywords()); ok {
+		d.Hints = append(d.Hints, suggest.Hint{Name: ident.Value, Suggestion: kw, Keyword: true})
+	}
+}
@@ -0,0 +1,132 @@
+package resolver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/parser"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	prg := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parse %q: %v", input, errs)
+	}
+	return prg
+}
+
+func TestResolveUses(t *testing.T) {
+	input := `
+let x = 1;
+let y = x + len;
+x * y;
+`
+	prg := parse(t, input)
+	info := Resolve(prg, []string{"len"})
+	if len(info.Diagnostics) != 0 {
+		t.Fatalf("want no diagnostics, got %v", info.Diagnostics)
+	}
+
+	xDecl := prg.Statements[0].(*ast.LetStatement)
+	yDecl := prg.Statements[1].(*ast.LetStatement)
+	if info.Defs[xDecl.Name].Node != xDecl {
+		t.Fatalf("want Defs[x].Node = %v, got %v", xDecl, info.Defs[xDecl.Name].Node)
+	}
+
+	cases := []struct {
+		ident *ast.Identifier
+		kind  RefKind
+		decl  *ast.LetStatement
+	}{
+		{yDecl.Value.(*ast.InfixExpression).Left.(*ast.Identifier), Local, xDecl},
+		{yDecl.Value.(*ast.InfixExpression).Right.(*ast.Identifier), Global, nil},
+		{prg.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression).Left.(*ast.Identifier), Local, xDecl},
+		{prg.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression).Right.(*ast.Identifier), Local, yDecl},
+	}
+	for _, c := range cases {
+		ref := info.Uses[c.ident]
+		if ref == nil {
+			t.Fatalf("want Uses[%s] to be set", c.ident)
+		}
+		if ref.Kind != c.kind {
+			t.Fatalf("want Uses[%s].Kind = %s, got %s", c.ident, c.kind, ref.Kind)
+		}
+		if c.decl != nil && ref.Decl.Node != c.decl {
+			t.Fatalf("want Uses[%s].Decl.Node = %v, got %v", c.ident, c.decl, ref.Decl.Node)
+		}
+	}
+
+	if names := info.Scopes[prg].Names(); len(names) != 3 {
+		t.Fatalf("want Scope.Names() = [len x y], got %v", names)
+	}
+}
+
+func TestResolveDiagnostics(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{"a + 1;", []string{"1:1: error: undefined: a"}},
+		// 宣言より前の使用は未定義になる
+		{"x; let x = 1;", []string{"1:1: error: undefined: x"}},
+		// 右辺は宣言の前に解決される
+		{"let x = x;", []string{"1:9: error: undefined: x"}},
+		{"let x = 1;\nlet x = 2;", []string{"2:5: error: x redeclared in this scope"}},
+		{"let len = 1;", []string{"1:5: warning: declaration of len shadows builtin len"}},
+	}
+
+	for _, c := range cases {
+		info := Resolve(parse(t, c.input), []string{"len"})
+		var actual []string
+		for _, d := range info.Diagnostics {
+			actual = append(actual, formatDiagnostic(c.input, d))
+		}
+		if len(actual) != len(c.expected) {
+			t.Fatalf("%q: want %q, got %q", c.input, c.expected, actual)
+		}
+		for i := range actual {
+			if actual[i] != c.expected[i] {
+				t.Fatalf("%q: want %q, got %q", c.input, c.expected[i], actual[i])
+			}
+		}
+	}
+}
+
+func TestResolveHints(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"let length = 1; lenght;", "unknown identifier `lenght`; did you mean identifier `length`?"},
+		// 組み込みの名前も候補になる
+		{"lne;", "unknown identifier `lne`; did you mean identifier `len`?"},
+		{"retrun;", "unknown identifier `retrun`; did you mean keyword `return`?"},
+		{"retrun 5;", "unknown identifier `retrun`; did you mean keyword `return`?"},
+		{"foo;", ""},
+	}
+
+	for _, c := range cases {
+		info := Resolve(parse(t, c.input), []string{"len"})
+		if len(info.Diagnostics) != 1 {
+			t.Fatalf("%q: want 1 diagnostic, got %v", c.input, info.Diagnostics)
+		}
+		var actual string
+		for _, h := range info.Diagnostics[0].Hints {
+			actual = h.String()
+		}
+		if actual != c.expected {
+			t.Fatalf("%q: want hint %q, got %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func formatDiagnostic(input string, d Diagnostic) string {
+	pos := token.NewFile("", input).Position(d.Pos)
+	return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Msg)
+}
@@ -0,0 +1,76 @@
+package resolver
+
+import (
+	"sort"
+
+	"github.com/hiroygo/go-interpreter/ast"
+)
+
+// Scope はレキシカルスコープを表す
+// 現在の構文ではプログラム全体のスコープと、その外側にある組み込みの名前のスコープだけができる
+// 関数やブロックが構文に加わったときは、それらのノードごとに子スコープを作る
+type Scope struct {
+	Parent *Scope
+	// スコープを作ったノード
+	// 組み込みの名前のスコープでは nil
+	Node  ast.Node
+	decls map[string]*Decl
+}
+
+func newScope(parent *Scope, node ast.Node) *Scope {
+	return &Scope{Parent: parent, Node: node, decls: map[string]*Decl{}}
+}
+
+// Decl は名前の宣言を表す
+type Decl struct {
+	Name string
+	// 宣言された識別子
+	// 組み込みの名前では nil
+	Ident *ast.Identifier
+	// 宣言したノード
+	// e.g. *ast.LetStatement
+	Node  ast.Node
+	Scope *Scope
+}
+
+// IsGlobal は d が組み込みの名前のときに true を返す
+func (d *Decl) IsGlobal() bool {
+	return d.Ident == nil
+}
+
+// LookupLocal は s で宣言された name を返す
+// 外側のスコープは探さない
+func (s *Scope) LookupLocal(name string) *Decl {
+	return s.decls[name]
+}
+
+// Lookup は s から外側に向かって name の宣言を探す
+func (s *Scope) Lookup(name string) *Decl {
+	for sc := s; sc != nil; sc = sc.Parent {
+		if d, ok := sc.decls[name]; ok {
+			return d
+		}
+	}
+	return nil
+}
+
+// Names は s と外側のスコープで見える名前を辞書順で返す
+func (s *Scope) Names() []string {
+	seen := map[string]bool{}
+	var names []string
+	for sc := s; sc != nil; sc = sc.Parent {
+		for n := range sc.decls {
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Scope) insert(d *Decl) {
+	d.Scope = s
+	s.decls[d.Name] = d
+}
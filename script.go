@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/hiroygo/go-interpreter/ast"
+	"github.com/hiroygo/go-interpreter/diagnostic"
+	"github.com/hiroygo/go-interpreter/evaluator"
+	"github.com/hiroygo/go-interpreter/lexer"
+	"github.com/hiroygo/go-interpreter/object"
+	"github.com/hiroygo/go-interpreter/parser"
+	"github.com/hiroygo/go-interpreter/token"
+)
+
+// 'run <file> [args...]'
+func runScript(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) < 1 {
+		fmt.Fprintln(stderr, usage)
+		return exitUsage
+	}
+	src, file, err := readSource(args[0], stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	env := object.NewEnvironment()
+	if err := setArgs(env, args[1:]); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	prg, ok := parseSource(src, file, stderr)
+	if !ok {
+		return exitError
+	}
+	if e, ok := evaluator.Eval(prg, env).(*object.Error); ok {
+		// 未定義の名前は、綴りの近い名前やキーワードの候補と一緒に表示する
+		if d, ok := diagnostic.FromUndefined(prg, env.Names(), e, parser.LanguageFromEnv()); ok {
+			diagnostic.NewRenderer(stderr).Render(stderr, file, src, d)
+			return exitError
+		}
+		fmt.Fprintf(stderr, "%s: %s\n", file.Position(e.Pos), e.Message)
+		return exitError
+	}
+	return exitOK
+}
+
+// setArgs はスクリプトの引数を arg1, arg2, ... と、その数を argc に束縛する
+// 言語に文字列が無いので、引数は整数に限る
+func setArgs(env *object.Environment, args []string) error {
+	env.Set("argc", &object.Integer{Value: int64(len(args))})
+	for i, a := range args {
+		v, err := strconv.ParseInt(a, 0, 64)
+		if err != nil {
+			return fmt.Errorf("argument %d (%q) is not an integer", i+1, a)
+		}
+		env.Set(fmt.Sprintf("arg%d", i+1), &object.Integer{Value: v})
+	}
+	return nil
+}
+
+// 'tokens <file>'
+func runTokens(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, usage)
+		return exitUsage
+	}
+	src, file, err := readSource(args[0], stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	ts, err := lexer.Tokenize(src, lexer.WithLanguage(parser.LanguageFromEnv()))
+	for _, t := range ts {
+		fmt.Fprintf(stdout, "%s\t%s\t%q\n", file.Position(t.Pos), t.Type, t.Literal)
+	}
+	if errs, ok := err.(lexer.ErrorList); ok {
+		diagnostic.NewRenderer(stderr).RenderLexerErrors(stderr, file, src, errs)
+		return exitError
+	}
+	return exitOK
+}
+
+// 'parse <file>'
+func runParse(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, usage)
+		return exitUsage
+	}
+	src, file, err := readSource(args[0], stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	prg, ok := parseSource(src, file, stderr)
+	if !ok {
+		return exitError
+	}
+	for _, s := range prg.Statements {
+		fmt.Fprintln(stdout, s.String())
+	}
+	return exitOK
+}
+
+// readSource は name のファイルを読む
+// name が '-' のときは stdin から読む
+func readSource(name string, stdin io.Reader) (string, *token.File, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if name == "-" {
+		name = "<stdin>"
+		b, err = io.ReadAll(stdin)
+	} else {
+		b, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	src := string(b)
+	return src, token.NewFile(name, src), nil
+}
+
+// parseSource は src を構文解析する
+// 構文エラーがあるときはソースの抜粋付きで stderr に出力し、false を返す
+func parseSource(src string, file *token.File, stderr io.Writer) (*ast.Program, bool) {
+	p := parser.New(lexer.New(src), parser.WithLanguage(parser.LanguageFromEnv()))
+	prg := p.ParseProgram()
+	errs := p.ErrorList()
+	diagnostic.NewRenderer(stderr).RenderParserErrors(stderr, file, src, errs)
+	return prg, len(errs) == 0
+}
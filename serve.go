@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hiroygo/go-interpreter/repl"
+)
+
+// 'serve [flags] <address>'
+// SIGINT か SIGTERM を受け取るとセッションを閉じて終了する
+func runServe(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	network := fs.String("net", "tcp", "network to listen on, tcp or unix")
+	maxSessions := fs.Int("max-sessions", 16, "maximum number of concurrent sessions, 0 for no limit")
+	idleTimeout := fs.Duration("idle-timeout", 0, "close sessions idle for this long, 0 to keep them open")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: go-interpreter serve [-net tcp|unix] [-max-sessions n] [-idle-timeout d] <address>")
+		return exitUsage
+	}
+
+	ln, err := net.Listen(*network, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "listening on %s %s\n", ln.Addr().Network(), ln.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := &repl.Server{MaxSessions: *maxSessions, IdleTimeout: *idleTimeout}
+	if err := s.Serve(ctx, ln); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
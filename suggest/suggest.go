@@ -0,0 +1,89 @@
+// suggest は綴りを誤った名前に近い候補を探す
+package suggest
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Hint は誤っていると思われる名前と、その直し方の候補を表す
+type Hint struct {
+	// ソースに書かれていた名前
+	// e.g. 'retrun'
+	Name string
+	// 候補の名前
+	// e.g. 'return'
+	Suggestion string
+	// 候補がキーワードのとき true
+	Keyword bool
+}
+
+// String は 'unknown identifier `retrun`; did you mean keyword `return`?' の形式で返す
+func (h Hint) String() string {
+	kind := "identifier"
+	if h.Keyword {
+		kind = "keyword"
+	}
+	return fmt.Sprintf("unknown identifier `%s`; did you mean %s `%s`?", h.Name, kind, h.Suggestion)
+}
+
+// Closest は candidates のうち name に最も近いものを返す
+// 近いものが無いときは false を返す
+// 距離が同じときは candidates で先にあるものを選ぶ
+func Closest(name string, candidates []string) (string, bool) {
+	best, bestDist := "", maxDistance(name)+1
+	for _, c := range candidates {
+		if c == name {
+			continue
+		}
+		// 候補のほとんどを書き換えるようなものは提案しない
+		// e.g. 'x' と 'if'
+		d := Distance(name, c)
+		if d >= utf8.RuneCountInString(c) {
+			continue
+		}
+		if d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best, best != ""
+}
+
+// maxDistance は name の誤りとして許す距離
+// 3 文字ごとに 1 文字の誤りを許し、短い名前でも 1 文字は許す
+func maxDistance(name string) int {
+	if d := utf8.RuneCountInString(name) / 3; d > 1 {
+		return d
+	}
+	return 1
+}
+
+// Distance は a と b の編集距離を返す
+// 挿入、削除、置換に加えて、隣り合う 2 文字の入れ替えも 1 回と数える
+// e.g. 'retrun' と 'return' は 1
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	// d[i][j] は s[:i] と t[:j] の距離
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
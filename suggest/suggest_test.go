@@ -0,0 +1,55 @@
+package suggest
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"fun", "fn", 1},
+		{"retrun", "return", 1},
+		{"lenght", "length", 1},
+		{"kitten", "sitting", 3},
+		{"関数", "関", 1},
+	}
+	for _, c := range cases {
+		if actual := Distance(c.a, c.b); actual != c.expected {
+			t.Errorf("Distance(%q, %q): want %d, got %d", c.a, c.b, c.expected, actual)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	keywords := []string{"else", "false", "fn", "if", "let", "return", "true"}
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{"retrun", "return"},
+		{"fun", "fn"},
+		{"ture", "true"},
+		{"esle", "else"},
+		// 完全に一致するものは提案しない
+		{"let", ""},
+		{"x", ""},
+		{"foo", ""},
+	}
+	for _, c := range cases {
+		actual, ok := Closest(c.name, keywords)
+		if actual != c.expected || ok != (c.expected != "") {
+			t.Errorf("Closest(%q): want %q, got (%q, %v)", c.name, c.expected, actual, ok)
+		}
+	}
+
+	// 距離はバイトではなく文字で比べる
+	japanese := []string{"真", "返す"}
+	if actual, ok := Closest("偽", japanese); ok {
+		t.Errorf("Closest(偽): want no suggestion, got %q", actual)
+	}
+	if actual, _ := Closest("返", japanese); actual != "返す" {
+		t.Errorf("Closest(返): want 返す, got %q", actual)
+	}
+}
@@ -0,0 +1,4 @@
+#!/usr/bin/env go-interpreter run
+// a comment
+let x = 1; // trailing
+x
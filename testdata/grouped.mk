@@ -0,0 +1,2 @@
+(1 + 2) * -(3 + 4);
+!(true == false);
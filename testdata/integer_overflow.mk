@@ -0,0 +1 @@
+let big = 9223372036854775808;
@@ -0,0 +1,3 @@
+let x = 5;
+let y = true;
+let foobar = x;
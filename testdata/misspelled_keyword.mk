@@ -0,0 +1,2 @@
+lett x = 5;
+retrun 5;
@@ -0,0 +1,4 @@
+-a * b + c;
+a + b * c + d / e - f;
+5 > 4 == 3 < 4;
+!true != false;
@@ -0,0 +1,2 @@
+return 5;
+return x + 1
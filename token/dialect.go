@@ -0,0 +1,142 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Dialect はキーワードの綴りの組を表す
+// 記号や識別子の規則はどの Dialect でも同じ
+type Dialect struct {
+	name     string
+	keywords map[string]TokenType
+	words    map[TokenType]string
+	// LookupIdent で使う keywords の完全ハッシュ表
+	table *perfectTable
+}
+
+// Standard は 'fn' や 'let' を使う標準のキーワード
+var Standard = mustDialect("standard", map[TokenType]string{
+	FUNCTION: "fn",
+	LET:      "let",
+	TRUE:     "true",
+	FALSE:    "false",
+	IF:       "if",
+	ELSE:     "else",
+	RETURN:   "return",
+})
+
+// Japanese は日本語のキーワード
+// e.g. '変数 x = もし (真) { 1 } でなければ { 2 };'
+var Japanese = mustDialect("japanese", map[TokenType]string{
+	FUNCTION: "関数",
+	LET:      "変数",
+	TRUE:     "真",
+	FALSE:    "偽",
+	IF:       "もし",
+	ELSE:     "でなければ",
+	RETURN:   "返す",
+})
+
+// NewDialect は words の綴りを使う Dialect を返す
+// words にはすべてのキーワードの種類が必要で、綴りは重複できない
+// 綴りは ASCII の識別子か、ASCII 以外の文字だけからなる単語にする
+// e.g. 'fn', '関数'
+func NewDialect(name string, words map[TokenType]string) (*Dialect, error) {
+	d := &Dialect{
+		name:     name,
+		keywords: map[string]TokenType{},
+		words:    map[TokenType]string{},
+	}
+	for t, w := range words {
+		if !isKeywordType(t) {
+			return nil, fmt.Errorf("dialect %s: %s is not a keyword", name, t)
+		}
+		if !isKeywordWord(w) {
+			return nil, fmt.Errorf("dialect %s: %q is not a valid keyword", name, w)
+		}
+		if prev, ok := d.keywords[w]; ok {
+			return nil, fmt.Errorf("dialect %s: %q is used for both %s and %s", name, w, prev, t)
+		}
+		d.keywords[w] = t
+		d.words[t] = w
+	}
+	for _, t := range keywords {
+		if _, ok := d.words[t]; !ok {
+			return nil, fmt.Errorf("dialect %s: missing a keyword for %s", name, t)
+		}
+	}
+	d.table = newPerfectTable(d.keywords)
+	return d, nil
+}
+
+func mustDialect(name string, words map[TokenType]string) *Dialect {
+	d, err := NewDialect(name, words)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func isKeywordType(t TokenType) bool {
+	for _, kt := range keywords {
+		if kt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeywordWord は w を字句解析したときに 1 つのトークンになるときに true を返す
+func isKeywordWord(w string) bool {
+	if w == "" {
+		return false
+	}
+	if w[0] >= utf8.RuneSelf {
+		for _, r := range w {
+			if r < utf8.RuneSelf || !unicode.IsLetter(r) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := 0; i < len(w); i++ {
+		c := w[i]
+		letter := 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+		digit := '0' <= c && c <= '9'
+		if !letter && !(digit && i > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Dialect) String() string {
+	return d.name
+}
+
+// LookupIdent は s が d のキーワードのときはその種類を、それ以外は IDENT を返す
+// 字句解析で識別子ごとに呼ばれるので、map ではなく完全ハッシュ表を引く
+func (d *Dialect) LookupIdent(s string) TokenType {
+	t, _ := d.table.lookup(s)
+	return t
+}
+
+// Keyword は t の d での綴りを返す
+// t がキーワードではないときは false を返す
+func (d *Dialect) Keyword(t TokenType) (string, bool) {
+	w, ok := d.words[t]
+	return w, ok
+}
+
+// Keywords は d のキーワードを辞書順で返す
+func (d *Dialect) Keywords() []string {
+	var ks []string
+	for k := range d.keywords {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
@@ -0,0 +1,50 @@
+package token
+
+import "testing"
+
+func TestNewDialect(t *testing.T) {
+	valid := map[TokenType]string{
+		FUNCTION: "fun", LET: "var", TRUE: "yes", FALSE: "no", IF: "when", ELSE: "otherwise", RETURN: "give",
+	}
+	d, err := NewDialect("custom", valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.LookupIdent("when") != IF || d.LookupIdent("if") != IDENT {
+		t.Fatalf("want LookupIdent(when) = IF and LookupIdent(if) = IDENT")
+	}
+	if w, ok := d.Keyword(RETURN); !ok || w != "give" {
+		t.Fatalf("want Keyword(RETURN) = give, got %q", w)
+	}
+	if _, ok := d.Keyword(IDENT); ok {
+		t.Fatal("want Keyword(IDENT) to fail")
+	}
+
+	invalid := []map[TokenType]string{
+		// キーワードが足りない
+		{FUNCTION: "fun"},
+		// 同じ綴り
+		{FUNCTION: "x", LET: "x", TRUE: "yes", FALSE: "no", IF: "when", ELSE: "otherwise", RETURN: "give"},
+		// キーワードではない種類
+		{FUNCTION: "fun", LET: "var", TRUE: "yes", FALSE: "no", IF: "when", ELSE: "otherwise", RETURN: "give", PLUS: "plus"},
+		// 1 つのトークンにならない綴り
+		{FUNCTION: "関x", LET: "var", TRUE: "yes", FALSE: "no", IF: "when", ELSE: "otherwise", RETURN: "give"},
+		{FUNCTION: "1fun", LET: "var", TRUE: "yes", FALSE: "no", IF: "when", ELSE: "otherwise", RETURN: "give"},
+	}
+	for _, words := range invalid {
+		if _, err := NewDialect("invalid", words); err == nil {
+			t.Errorf("NewDialect(%v): want an error", words)
+		}
+	}
+}
+
+func TestStandardDialect(t *testing.T) {
+	for _, k := range Keywords() {
+		if Standard.LookupIdent(k) != LookupIdent(k) {
+			t.Errorf("want Standard.LookupIdent(%s) = %s", k, LookupIdent(k))
+		}
+	}
+	if len(Japanese.Keywords()) != len(Keywords()) {
+		t.Fatalf("want %d Japanese keywords, got %v", len(Keywords()), Japanese.Keywords())
+	}
+}
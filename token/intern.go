@@ -0,0 +1,43 @@
+package token
+
+import (
+	"strings"
+	"sync"
+)
+
+// Interner は同じ綴りの識別子を 1 つの文字列にまとめる
+// 綴りはソースから複製して覚えるので、字句解析したソースを持ち続けない
+// たくさんのファイルの AST を残すときに、ソースを解放して名前ごとに 1 つの文字列だけを残す
+// ゼロ値のまま使える
+// 並行に構文解析する Lexer で共有できるように、並行に使うことができる
+type Interner struct {
+	mu    sync.Mutex
+	names map[string]string
+}
+
+func NewInterner() *Interner {
+	return &Interner{}
+}
+
+// Intern は s と同じ綴りの文字列を返す
+// 初めての綴りのときは s を複製して覚え、複製を返す
+func (in *Interner) Intern(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if name, ok := in.names[s]; ok {
+		return name
+	}
+	if in.names == nil {
+		in.names = map[string]string{}
+	}
+	name := strings.Clone(s)
+	in.names[name] = name
+	return name
+}
+
+// Len は覚えている綴りの数を返す
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.names)
+}
@@ -0,0 +1,68 @@
+package token
+
+// perfectTable はキーワードの綴りから種類を引く完全ハッシュ表
+// 綴りの組は Dialect を作るときに決まるので、衝突しない seed を探して表を作る
+// 引くときは文字列を作らずにハッシュを計算し、1 回の比較で決まる
+type perfectTable struct {
+	seed    uint32
+	mask    uint32
+	maxLen  int
+	entries []perfectEntry
+}
+
+type perfectEntry struct {
+	word string
+	t    TokenType
+}
+
+// 表の大きさを倍にする前に試す seed の数
+const maxSeeds = 1 << 12
+
+func newPerfectTable(keywords map[string]TokenType) *perfectTable {
+	size := 1
+	for size < 2*len(keywords) {
+		size <<= 1
+	}
+	for {
+		for seed := uint32(0); seed < maxSeeds; seed++ {
+			if pt, ok := tryPerfectTable(keywords, seed, size); ok {
+				return pt
+			}
+		}
+		size <<= 1
+	}
+}
+
+func tryPerfectTable(keywords map[string]TokenType, seed uint32, size int) (*perfectTable, bool) {
+	pt := &perfectTable{seed: seed, mask: uint32(size - 1), entries: make([]perfectEntry, size)}
+	for w, t := range keywords {
+		e := &pt.entries[pt.slot(w)]
+		if e.word != "" {
+			return nil, false
+		}
+		*e = perfectEntry{word: w, t: t}
+		pt.maxLen = max(pt.maxLen, len(w))
+	}
+	return pt, true
+}
+
+// slot は seed を混ぜた FNV-1a のハッシュで s の位置を返す
+func (pt *perfectTable) slot(s string) uint32 {
+	h := uint32(2166136261) ^ pt.seed
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h & pt.mask
+}
+
+func (pt *perfectTable) lookup(s string) (TokenType, bool) {
+	if len(s) == 0 || len(s) > pt.maxLen {
+		return IDENT, false
+	}
+	e := &pt.entries[pt.slot(s)]
+	if e.word != s {
+		return IDENT, false
+	}
+	return e.t, true
+}
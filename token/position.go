@@ -0,0 +1,131 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Position は Pos を人が読める形にしたもの
+// Line と Column は 1 から始まり、Column はバイト単位で数える
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String は 'file:line:col' の形式で位置を返す
+// ファイル名が無いときは 'line:col' になる
+func (p Position) String() string {
+	if !p.IsValid() {
+		if p.Filename != "" {
+			return p.Filename
+		}
+		return "-"
+	}
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+// File はソースの行の先頭位置を保持し、Pos を Position に変換する
+type File struct {
+	name string
+	size int
+	// 各行の先頭のバイトオフセット
+	lines []int
+}
+
+func NewFile(name, src string) *File {
+	f := &File{name: name, size: len(src), lines: []int{0}}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			f.lines = append(f.lines, i+1)
+		}
+	}
+	return f
+}
+
+func (f *File) Name() string {
+	return f.name
+}
+
+func (f *File) Size() int {
+	return f.size
+}
+
+// LineStart は line 行目(1 から始まる)の先頭のバイトオフセットを返す
+func (f *File) LineStart(line int) int {
+	if line < 1 || len(f.lines) < line {
+		return -1
+	}
+	return f.lines[line-1]
+}
+
+func (f *File) Position(p Pos) Position {
+	if !p.IsValid() {
+		return Position{Filename: f.name}
+	}
+	offset := p.Offset()
+	if offset > f.size {
+		offset = f.size
+	}
+	// offset 以下で最大の行頭を探す
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	return Position{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     i + 1,
+		Column:   offset - f.lines[i] + 1,
+	}
+}
+
+// FileRegistry は複数のファイルの File を名前で保持する
+// 複数のファイルをまとめて扱うツールで、診断の位置を表示するときに使う
+// go/token の FileSet と違い、ファイルを 1 つの位置の空間にまとめない
+// Pos はファイルごとの位置なので、Pos からファイルは引けない
+// 並行に使うことができる
+type FileRegistry struct {
+	mu    sync.Mutex
+	files map[string]*File
+}
+
+func NewFileRegistry() *FileRegistry {
+	return &FileRegistry{files: map[string]*File{}}
+}
+
+// AddFile は src から File を作って s に加える
+// 同じ名前の File があるときは置き換える
+func (s *FileRegistry) AddFile(name, src string) *File {
+	f := NewFile(name, src)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = f
+	return f
+}
+
+// File は name の File を返す
+// 無いときは nil を返す
+func (s *FileRegistry) File(name string) *File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files[name]
+}
+
+// Files は File を名前の順に返す
+func (s *FileRegistry) Files() []*File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fs := make([]*File, 0, len(s.files))
+	for _, f := range s.files {
+		fs = append(fs, f)
+	}
+	sort.Slice(fs, func(i, j int) bool { return fs[i].name < fs[j].name })
+	return fs
+}
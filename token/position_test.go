@@ -0,0 +1,46 @@
+package token
+
+import "testing"
+
+func TestFilePosition(t *testing.T) {
+	f := NewFile("a.mk", "let x = 1;\n\nx + 2;\n")
+	cases := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "a.mk:1:1"},
+		{4, "a.mk:1:5"},
+		{10, "a.mk:1:11"},
+		{11, "a.mk:2:1"},
+		{12, "a.mk:3:1"},
+		{16, "a.mk:3:5"},
+		{19, "a.mk:4:1"},
+	}
+	for _, c := range cases {
+		actual := f.Position(PosFromOffset(c.offset)).String()
+		if actual != c.expected {
+			t.Errorf("want Position(%d) = %q, got %q", c.offset, c.expected, actual)
+		}
+	}
+
+	if actual := f.Position(NoPos).String(); actual != "a.mk" {
+		t.Errorf("want Position(NoPos) = %q, got %q", "a.mk", actual)
+	}
+}
+
+func TestFileRegistry(t *testing.T) {
+	s := NewFileRegistry()
+	s.AddFile("b.mk", "x;\n")
+	a := s.AddFile("a.mk", "let x = 1;\n")
+	if s.File("a.mk") != a || s.File("c.mk") != nil {
+		t.Fatal("want File to look up files by name")
+	}
+	// 同じ名前のときは置き換える
+	b := s.AddFile("b.mk", "y;\nz;\n")
+	if got := s.File("b.mk").Position(PosFromOffset(3)).String(); got != "b.mk:2:1" {
+		t.Fatalf("want the replaced file, got %s", got)
+	}
+	if fs := s.Files(); len(fs) != 2 || fs[0] != a || fs[1] != b {
+		t.Fatalf("want the files sorted by name, got %v", fs)
+	}
+}
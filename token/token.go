@@ -1,49 +1,126 @@
 package token
 
+import (
+	"sort"
+	"strconv"
+)
+
+// TokenType はトークンの種類を表す
+// 比較や配列の添字に使えるように整数にしている
+// String() はデバッグやエラーメッセージのための名前を返す
+type TokenType uint8
+
 const (
-	ILLEGAL = "ILLEGAL"
-	EOF     = "EOF"
+	ILLEGAL TokenType = iota
+	EOF
 
 	// 識別子(変数名)を表す
-	IDENT = "IDENT"
+	IDENT
+	INT
 
 	// 記号を表す
-	ASSIGN    = "="
-	PLUS      = "+"
-	COMMA     = ","
-	SEMICOLON = ";"
-	LPAREN    = "("
-	RPAREN    = ")"
-	LBRACE    = "{"
-	RBRACE    = "}"
-	MINUS     = "-"
-	BANG      = "!"
-	ASTERISK  = "*"
-	SLASH     = "/"
-	LT        = "<"
-	GT        = ">"
-	EQ        = "=="
-	NOT_EQ    = "!="
+	ASSIGN
+	PLUS
+	COMMA
+	SEMICOLON
+	LPAREN
+	RPAREN
+	LBRACE
+	RBRACE
+	MINUS
+	BANG
+	ASTERISK
+	SLASH
+	LT
+	GT
+	EQ
+	NOT_EQ
 
 	// 予約語を表す
 	// "return", "let" など
-	INT      = "INT"
-	FUNCTION = "FUNCTION"
-	LET      = "LET"
-	TRUE     = "TRUE"
-	FALSE    = "FALSE"
-	IF       = "IF"
-	ELSE     = "ELSE"
-	RETURN   = "RETURN"
+	FUNCTION
+	LET
+	TRUE
+	FALSE
+	IF
+	ELSE
+	RETURN
+
+	// 種類の数
+	// 種類ごとの表を作るときに使う
+	NumTypes int = iota
 )
 
-// デバッグしやすいように string にしておく
-type TokenType string
+// 記号は記号そのものを名前にする
+var typeNames = [...]string{
+	ILLEGAL:   "ILLEGAL",
+	EOF:       "EOF",
+	IDENT:     "IDENT",
+	INT:       "INT",
+	ASSIGN:    "=",
+	PLUS:      "+",
+	COMMA:     ",",
+	SEMICOLON: ";",
+	LPAREN:    "(",
+	RPAREN:    ")",
+	LBRACE:    "{",
+	RBRACE:    "}",
+	MINUS:     "-",
+	BANG:      "!",
+	ASTERISK:  "*",
+	SLASH:     "/",
+	LT:        "<",
+	GT:        ">",
+	EQ:        "==",
+	NOT_EQ:    "!=",
+	FUNCTION:  "FUNCTION",
+	LET:       "LET",
+	TRUE:      "TRUE",
+	FALSE:     "FALSE",
+	IF:        "IF",
+	ELSE:      "ELSE",
+	RETURN:    "RETURN",
+}
+
+func (t TokenType) String() string {
+	if int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return "TokenType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Pos はソース中の位置を表す
+// 値は先頭からのバイトオフセット + 1 で、0 は位置が無いことを表す
+// go/token と同じように、ゼロ値のノードでも位置の有無を判定できるようにしている
+type Pos int
+
+const NoPos Pos = 0
+
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// Offset は p を 0 から始まるバイトオフセットに変換する
+func (p Pos) Offset() int {
+	return int(p) - 1
+}
+
+// PosFromOffset は 0 から始まるバイトオフセットを Pos に変換する
+func PosFromOffset(offset int) Pos {
+	return Pos(offset + 1)
+}
 
 // 変数トークンのときは Token{Type: IDENT, Literal: "foo"} のようになる
 type Token struct {
 	Type    TokenType
 	Literal string
+	// トークンの先頭の位置
+	Pos Pos
+}
+
+// End はトークンの直後の位置を返す
+func (t Token) End() Pos {
+	return t.Pos + Pos(len(t.Literal))
 }
 
 var keywords = map[string]TokenType{
@@ -57,8 +134,15 @@ var keywords = map[string]TokenType{
 }
 
 func LookupIdent(s string) TokenType {
-	if v, ok := keywords[s]; ok {
-		return v
+	return Standard.LookupIdent(s)
+}
+
+// Keywords はキーワードを辞書順で返す
+func Keywords() []string {
+	var ks []string
+	for k := range keywords {
+		ks = append(ks, k)
 	}
-	return IDENT
+	sort.Strings(ks)
+	return ks
 }
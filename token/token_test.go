@@ -0,0 +1,87 @@
+package token
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestTokenTypeString(t *testing.T) {
+	tests := []struct {
+		t    TokenType
+		want string
+	}{
+		{ILLEGAL, "ILLEGAL"},
+		{EOF, "EOF"},
+		{IDENT, "IDENT"},
+		{ASSIGN, "="},
+		{EQ, "=="},
+		{NOT_EQ, "!="},
+		{FUNCTION, "FUNCTION"},
+		{RETURN, "RETURN"},
+		{TokenType(NumTypes), "TokenType(27)"},
+	}
+	for _, tt := range tests {
+		if got := tt.t.String(); got != tt.want {
+			t.Errorf("TokenType(%d).String(): want %q, got %q", int(tt.t), tt.want, got)
+		}
+	}
+
+	// すべての種類に名前がある
+	seen := map[string]bool{}
+	for i := 0; i < NumTypes; i++ {
+		name := TokenType(i).String()
+		if name == "" || seen[name] {
+			t.Errorf("TokenType(%d): want a unique name, got %q", i, name)
+		}
+		seen[name] = true
+	}
+}
+
+func TestLookupIdent(t *testing.T) {
+	for _, d := range []*Dialect{Standard, Japanese} {
+		for w, want := range d.keywords {
+			if got := d.LookupIdent(w); got != want {
+				t.Errorf("%s: LookupIdent(%q): want %s, got %s", d, w, want, got)
+			}
+			// 綴りの一部や前後に文字を足したものはキーワードではない
+			for _, s := range []string{w[:len(w)-1], w + "x", "x" + w, w + w} {
+				if got := d.LookupIdent(s); got != IDENT {
+					t.Errorf("%s: LookupIdent(%q): want IDENT, got %s", d, s, got)
+				}
+			}
+		}
+		if got := d.LookupIdent(""); got != IDENT {
+			t.Errorf("%s: LookupIdent(\"\"): want IDENT, got %s", d, got)
+		}
+	}
+}
+
+func TestLookupIdentAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, s := range []string{"let", "return", "foo", "返す"} {
+			Standard.LookupIdent(s)
+			Japanese.LookupIdent(s)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("want LookupIdent not to allocate, got %v allocs", allocs)
+	}
+}
+
+func TestInterner(t *testing.T) {
+	in := NewInterner()
+	src := "foo foo bar"
+	a := in.Intern(src[0:3])
+	b := in.Intern(src[4:7])
+	if a != "foo" || b != "foo" || unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Fatal("want the same string for the same name")
+	}
+	// ソースを持ち続けないように複製を覚える
+	if unsafe.StringData(a) == unsafe.StringData(src) {
+		t.Fatal("want a copy of the name, not a substring of the source")
+	}
+	in.Intern(src[8:11])
+	if in.Len() != 2 {
+		t.Fatalf("want 2 names, got %d", in.Len())
+	}
+}